@@ -0,0 +1,265 @@
+package ev3lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PID Gains                                                                  //
+////////////////////////////////////////////////////////////////////////////////
+
+// PIDGains is a set of gains for a PIDController.
+type PIDGains struct {
+	Kp float64 `json:"kp"`
+	Ki float64 `json:"ki"`
+	Kd float64 `json:"kd"`
+}
+
+// NewController creates a PID controller with the gains.
+func (g PIDGains) NewController() *PIDController {
+	return NewPIDController(g.Kp, g.Ki, g.Kd)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Autotune Result                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// AutotuneResult holds the ultimate gain and oscillation period measured by a relay experiment.
+type AutotuneResult struct {
+	// UltimateGain is the proportional gain at which the loop oscillates steadily.
+	UltimateGain float64 `json:"ultimateGain"`
+
+	// Period is the oscillation period in seconds.
+	Period float64 `json:"period"`
+
+	// Amplitude is half the peak to peak oscillation of the measurement.
+	Amplitude float64 `json:"amplitude"`
+}
+
+// ZieglerNichols returns the classic Ziegler-Nichols gains, which respond quickly but overshoot.
+func (r AutotuneResult) ZieglerNichols() PIDGains {
+	kp := 0.6 * r.UltimateGain
+	return PIDGains{Kp: kp, Ki: kp / (r.Period / 2), Kd: kp * r.Period / 8}
+}
+
+// TyreusLuyben returns the Tyreus-Luyben gains, which overshoot less and suit most mechanisms better.
+func (r AutotuneResult) TyreusLuyben() PIDGains {
+	kp := r.UltimateGain / 2.2
+	return PIDGains{Kp: kp, Ki: kp / (2.2 * r.Period), Kd: kp * r.Period / 6.3}
+}
+
+type autotuneFileEntry struct {
+	AutotuneResult
+	ZieglerNichols PIDGains `json:"zieglerNichols"`
+	TyreusLuyben   PIDGains `json:"tyreusLuyben"`
+}
+
+func readAutotuneFile(path string) (map[string]autotuneFileEntry, error) {
+	entries := map[string]autotuneFileEntry{}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return entries, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// SaveAutotuneResult saves a result under a name in a JSON file, keeping results with other names.
+// The suggested gains are saved alongside for reference.
+func SaveAutotuneResult(path, name string, result AutotuneResult) error {
+	entries, err := readAutotuneFile(path)
+	if err != nil {
+		return err
+	}
+
+	entries[name] = autotuneFileEntry{AutotuneResult: result, ZieglerNichols: result.ZieglerNichols(), TyreusLuyben: result.TyreusLuyben()}
+
+	b, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// LoadAutotuneResult loads a result saved under a name, e.g. to build a controller in a config.
+func LoadAutotuneResult(path, name string) (AutotuneResult, error) {
+	entries, err := readAutotuneFile(path)
+	if err != nil {
+		return AutotuneResult{}, err
+	}
+
+	entry, found := entries[name]
+	if !found {
+		return AutotuneResult{}, fmt.Errorf("no autotune result for %v in %v", name, path)
+	}
+	return entry.AutotuneResult, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Relay Autotune Command                                                     //
+////////////////////////////////////////////////////////////////////////////////
+
+// AutotuneConfig sets up a relay autotune experiment.
+type AutotuneConfig struct {
+	// Name identifies the result when printed and saved.
+	Name string
+
+	// Setpoint is the measurement to oscillate around, relative to the measurement when the command starts.
+	Setpoint float64
+
+	// RelayOutput is the power switched between, from 0 to 1.
+	RelayOutput float64
+
+	// Hysteresis stops noise switching the relay, set it a little above the sensor noise.
+	Hysteresis float64
+
+	// Cycles is the number of oscillations measured, after a first one which is ignored.
+	Cycles int
+
+	// Brick is used to show the result on the LCD, if not nil.
+	Brick *EV3Brick
+
+	// File is the JSON file the result is saved to, if not empty.
+	File string
+}
+
+// DefaultAutotuneConfig returns a config oscillating around the starting measurement for 4 cycles, with no hysteresis.
+func DefaultAutotuneConfig(name string, relayOutput float64) AutotuneConfig {
+	return AutotuneConfig{Name: name, RelayOutput: relayOutput, Cycles: 4}
+}
+
+type relayAutotuneCommand struct {
+	DefaultCommand
+
+	measure func() float64
+	output  func(float64)
+	config  AutotuneConfig
+
+	target   float64
+	relay    float64
+	high     float64
+	low      float64
+	lastRise time.Time
+
+	periods, amplitudes []float64
+}
+
+func (c *relayAutotuneCommand) Init() {
+	current := c.measure()
+	c.target = current + c.config.Setpoint
+
+	c.relay = c.config.RelayOutput
+	if current > c.target {
+		c.relay = -c.config.RelayOutput
+	}
+
+	c.high, c.low = math.Inf(-1), math.Inf(1)
+	c.lastRise = time.Time{}
+	c.periods = c.periods[:0]
+	c.amplitudes = c.amplitudes[:0]
+}
+
+func (c *relayAutotuneCommand) Run() {
+	current := c.measure()
+	c.high = max(c.high, current)
+	c.low = min(c.low, current)
+
+	if c.relay > 0 && current > c.target+c.config.Hysteresis {
+		c.relay = -c.config.RelayOutput
+	} else if c.relay < 0 && current < c.target-c.config.Hysteresis {
+		// Each switch back to positive output completes a cycle
+		c.relay = c.config.RelayOutput
+
+		now := Now()
+		if !c.lastRise.IsZero() {
+			c.periods = append(c.periods, now.Sub(c.lastRise).Seconds())
+			c.amplitudes = append(c.amplitudes, (c.high-c.low)/2)
+		}
+		c.lastRise = now
+		c.high, c.low = current, current
+	}
+
+	c.output(c.relay)
+}
+
+func (c *relayAutotuneCommand) result() AutotuneResult {
+	// The first cycle starts from rest, so it is not measured
+	periods, amplitudes := c.periods[1:], c.amplitudes[1:]
+
+	r := AutotuneResult{}
+	for i := range periods {
+		r.Period += periods[i] / float64(len(periods))
+		r.Amplitude += amplitudes[i] / float64(len(amplitudes))
+	}
+
+	if r.Amplitude > 0 {
+		r.UltimateGain = 4 * c.config.RelayOutput / (math.Pi * r.Amplitude)
+	}
+	return r
+}
+
+func (c *relayAutotuneCommand) End(interrupted bool) {
+	c.output(0)
+
+	if interrupted {
+		return
+	}
+
+	r := c.result()
+	zn, tl := r.ZieglerNichols(), r.TyreusLuyben()
+
+	fmt.Printf("autotune %v: Ku %.4g, Tu %.3gs, amplitude %.3g\n", c.config.Name, r.UltimateGain, r.Period, r.Amplitude)
+	fmt.Printf("  Ziegler-Nichols kp %.4g ki %.4g kd %.4g\n", zn.Kp, zn.Ki, zn.Kd)
+	fmt.Printf("  Tyreus-Luyben   kp %.4g ki %.4g kd %.4g\n", tl.Kp, tl.Ki, tl.Kd)
+
+	if c.config.Brick != nil {
+		c.config.Brick.PrintScreen(
+			c.config.Name,
+			fmt.Sprintf("Ku %.3g", r.UltimateGain),
+			fmt.Sprintf("Tu %.3gs", r.Period),
+			fmt.Sprintf("P %.3g", tl.Kp),
+			fmt.Sprintf("I %.3g D %.3g", tl.Ki, tl.Kd),
+		)
+	}
+
+	if c.config.File != "" {
+		if err := SaveAutotuneResult(c.config.File, c.config.Name, r); err != nil {
+			fmt.Printf("autotune %v: could not save result: %v\n", c.config.Name, err)
+		}
+	}
+}
+
+func (c *relayAutotuneCommand) IsDone() bool {
+	return len(c.periods) > max(c.config.Cycles, 1)
+}
+
+// NewRelayAutotuneCommand switches the output between plus and minus the relay output to make the measurement oscillate around the setpoint.
+// Once enough cycles have been measured, the result is printed, shown on the LCD and saved according to the config.
+// The output should move the measurement upwards when positive.
+func NewRelayAutotuneCommand(measure func() float64, output func(float64), config AutotuneConfig) *Command {
+	return NewCommand(&relayAutotuneCommand{measure: measure, output: output, config: config})
+}
+
+// AutotuneCommand tunes a position controller for the motor, with the setpoint in degrees.
+func (m *Motor) AutotuneCommand(config AutotuneConfig) *Command {
+	return NewRelayAutotuneCommand(m.Position, m.Set, config)
+}
+
+// AutotuneHeadingCommand tunes the heading controller of the drivetrain by turning on the spot, with the setpoint in degrees.
+func (d *DifferentialDrive) AutotuneHeadingCommand(config AutotuneConfig) *Command {
+	return NewRelayAutotuneCommand(d.Heading, func(power float64) {
+		d.SetTank(power, -power)
+	}, config).Requires(d.Subsystem)
+}
@@ -11,3 +11,67 @@ type EV3Brick struct {
 func NewEV3BrickBase(e EV3BrickInterface) *EV3Brick {
 	return &EV3Brick{e}
 }
+
+// Err returns the first error reading the brick since the last call to Err.
+func (e *EV3Brick) Err() error {
+	return errOf(e.EV3BrickInterface)
+}
+
+func (e *EV3Brick) VoltageErr() (float64, error) {
+	if b, ok := e.EV3BrickInterface.(interface{ VoltageErr() (float64, error) }); ok {
+		return b.VoltageErr()
+	}
+	return e.Voltage(), e.Err()
+}
+
+func (e *EV3Brick) CurrentErr() (float64, error) {
+	if b, ok := e.EV3BrickInterface.(interface{ CurrentErr() (float64, error) }); ok {
+		return b.CurrentErr()
+	}
+	return e.Current(), e.Err()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Sound Commands                                                             //
+////////////////////////////////////////////////////////////////////////////////
+
+type soundCommand struct {
+	DefaultCommand
+
+	brick *EV3Brick
+	play  func()
+}
+
+func (s *soundCommand) Init() {
+	s.play()
+}
+
+func (s *soundCommand) End(interrupted bool) {
+	if interrupted {
+		s.brick.StopSound()
+	}
+}
+
+func (s *soundCommand) IsDone() bool {
+	return !s.brick.IsSoundPlaying()
+}
+
+// BeepCommand beeps at a frequency in Hz for a duration in seconds, finishing when the beep does.
+func (e *EV3Brick) BeepCommand(frequency, duration float64) *Command {
+	return NewCommand(&soundCommand{brick: e, play: func() { e.Beep(frequency, duration) }})
+}
+
+// PlayNotesCommand plays notes at a tempo in quarter notes per minute, finishing when they do.
+func (e *EV3Brick) PlayNotesCommand(notes []EV3Note, tempo float64) *Command {
+	return NewCommand(&soundCommand{brick: e, play: func() { e.PlayNotes(notes, tempo) }})
+}
+
+// SpeakTextCommand speaks text, finishing when it has been spoken.
+func (e *EV3Brick) SpeakTextCommand(text string) *Command {
+	return NewCommand(&soundCommand{brick: e, play: func() { e.SpeakText(text) }})
+}
+
+// PlayWAVCommand plays a WAV file, finishing when it does.
+func (e *EV3Brick) PlayWAVCommand(path string) *Command {
+	return NewCommand(&soundCommand{brick: e, play: func() { e.PlayWAV(path) }})
+}
@@ -0,0 +1,75 @@
+package ev3lib
+
+import "time"
+
+////////////////////////////////////////////////////////////////////////////////
+// Clock Interface                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// Clock provides the current time to commands and runners, allowing time to be simulated.
+type Clock interface {
+	Now() time.Time
+	Sleep(dur time.Duration)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// System Clock                                                               //
+////////////////////////////////////////////////////////////////////////////////
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(dur time.Duration) {
+	time.Sleep(dur)
+}
+
+var clock Clock = systemClock{}
+
+// SetClock sets the clock used by all commands and runners. Defaults to the system clock.
+func SetClock(c Clock) {
+	clock = c
+}
+
+// GetClock returns the clock used by all commands and runners.
+func GetClock() Clock {
+	return clock
+}
+
+// Now returns the current time according to the current clock.
+func Now() time.Time {
+	return clock.Now()
+}
+
+// Since returns the time elapsed since t according to the current clock.
+func Since(t time.Time) time.Duration {
+	return clock.Now().Sub(t)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Interval Timer                                                             //
+////////////////////////////////////////////////////////////////////////////////
+
+// intervalTimer waits for fixed intervals on the current clock, similar to a time.Ticker.
+type intervalTimer struct {
+	next     time.Time
+	interval time.Duration
+}
+
+func newIntervalTimer(interval time.Duration) *intervalTimer {
+	return &intervalTimer{next: clock.Now().Add(interval), interval: interval}
+}
+
+// wait blocks until the next interval. Missed intervals are dropped.
+func (t *intervalTimer) wait() {
+	now := clock.Now()
+	if t.next.After(now) {
+		clock.Sleep(t.next.Sub(now))
+		t.next = t.next.Add(t.interval)
+		return
+	}
+
+	t.next = now.Add(t.interval)
+}
@@ -0,0 +1,312 @@
+package ev3lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Color Calibration                                                          //
+////////////////////////////////////////////////////////////////////////////////
+
+// calibrationSamples is the number of readings averaged when calibrating.
+const calibrationSamples = 10
+
+// ColorCalibration holds the readings of a color sensor over black and white, and over named mat colors.
+// Named colors are stored normalized against black and white.
+type ColorCalibration struct {
+	Black float64 `json:"black"`
+	White float64 `json:"white"`
+
+	BlackRGB [3]float64 `json:"blackRGB"`
+	WhiteRGB [3]float64 `json:"whiteRGB"`
+
+	Colors map[string][3]float64 `json:"colors,omitempty"`
+}
+
+// NewColorCalibration creates a calibration which leaves readings unchanged.
+func NewColorCalibration() *ColorCalibration {
+	return &ColorCalibration{White: 1, WhiteRGB: [3]float64{1, 1, 1}, Colors: map[string][3]float64{}}
+}
+
+func normalize(v, black, white float64) float64 {
+	if white <= black {
+		return v
+	}
+	return Clamp((v-black)/(white-black), 0, 1)
+}
+
+// NormalizeReflection scales a raw reflection so black is 0 and white is 1.
+func (c *ColorCalibration) NormalizeReflection(reflection float64) float64 {
+	return normalize(reflection, c.Black, c.White)
+}
+
+// NormalizeRGB scales each raw channel so black is 0 and white is 1.
+func (c *ColorCalibration) NormalizeRGB(r, g, b float64) (float64, float64, float64) {
+	return normalize(r, c.BlackRGB[0], c.WhiteRGB[0]),
+		normalize(g, c.BlackRGB[1], c.WhiteRGB[1]),
+		normalize(b, c.BlackRGB[2], c.WhiteRGB[2])
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Color Sensor Calibration                                                   //
+////////////////////////////////////////////////////////////////////////////////
+
+// Reflection returns the reflected light from 0 to 1, normalized against black and white once calibrated.
+func (c *ColorSensor) Reflection() float64 {
+	return c.normalizeReflection(c.RawReflection())
+}
+
+// ReflectionErr returns the normalized reflection like Reflection, and the error reading it.
+func (c *ColorSensor) ReflectionErr() (float64, error) {
+	r, err := c.RawReflectionErr()
+	return c.normalizeReflection(r), err
+}
+
+// GetRGB returns the measured color with each channel from 0 to 1, normalized against black and white once calibrated.
+func (c *ColorSensor) GetRGB() (float64, float64, float64) {
+	return c.normalizeRGB(c.RawRGB())
+}
+
+// GetRGBErr returns the normalized color like GetRGB, and the error reading it.
+func (c *ColorSensor) GetRGBErr() (float64, float64, float64, error) {
+	r, g, b, err := c.RawRGBErr()
+	r, g, b = c.normalizeRGB(r, g, b)
+	return r, g, b, err
+}
+
+func (c *ColorSensor) normalizeReflection(r float64) float64 {
+	if c.calibration == nil {
+		return r
+	}
+	return c.calibration.NormalizeReflection(r)
+}
+
+func (c *ColorSensor) normalizeRGB(r, g, b float64) (float64, float64, float64) {
+	if c.calibration == nil {
+		return r, g, b
+	}
+	return c.calibration.NormalizeRGB(r, g, b)
+}
+
+// RawReflection returns the reflection read from the sensor, ignoring calibration.
+func (c *ColorSensor) RawReflection() float64 {
+	return c.ColorSensorInterface.Reflection()
+}
+
+// RawRGB returns the color read from the sensor, ignoring calibration.
+func (c *ColorSensor) RawRGB() (float64, float64, float64) {
+	return c.ColorSensorInterface.GetRGB()
+}
+
+// Calibration returns the calibration of the sensor, or nil if it has not been calibrated.
+func (c *ColorSensor) Calibration() *ColorCalibration {
+	return c.calibration
+}
+
+func (c *ColorSensor) SetCalibration(calibration *ColorCalibration) {
+	c.calibration = calibration
+}
+
+// ClearCalibration returns the sensor to raw readings.
+func (c *ColorSensor) ClearCalibration() {
+	c.calibration = nil
+}
+
+func (c *ColorSensor) calibrationOrNew() *ColorCalibration {
+	if c.calibration == nil {
+		c.calibration = NewColorCalibration()
+	}
+	return c.calibration
+}
+
+// sampleRaw averages raw readings of reflection and color.
+func (c *ColorSensor) sampleRaw() (reflection float64, rgb [3]float64) {
+	for i := 0; i < calibrationSamples; i++ {
+		reflection += c.RawReflection() / calibrationSamples
+
+		r, g, b := c.RawRGB()
+		rgb[0] += r / calibrationSamples
+		rgb[1] += g / calibrationSamples
+		rgb[2] += b / calibrationSamples
+	}
+	return reflection, rgb
+}
+
+// CalibrateBlack samples the sensor over black.
+func (c *ColorSensor) CalibrateBlack() {
+	cal := c.calibrationOrNew()
+	cal.Black, cal.BlackRGB = c.sampleRaw()
+}
+
+// CalibrateWhite samples the sensor over white.
+func (c *ColorSensor) CalibrateWhite() {
+	cal := c.calibrationOrNew()
+	cal.White, cal.WhiteRGB = c.sampleRaw()
+}
+
+// CalibrateColor samples the sensor over a named mat color. Calibrate black and white first.
+func (c *ColorSensor) CalibrateColor(name string) {
+	cal := c.calibrationOrNew()
+	_, rgb := c.sampleRaw()
+
+	r, g, b := cal.NormalizeRGB(rgb[0], rgb[1], rgb[2])
+	if cal.Colors == nil {
+		cal.Colors = map[string][3]float64{}
+	}
+	cal.Colors[name] = [3]float64{r, g, b}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Calibration Files                                                          //
+////////////////////////////////////////////////////////////////////////////////
+
+// DefaultColorCalibrationFile is the file calibrations are saved to by default, relative to the program.
+const DefaultColorCalibrationFile = "colorCalibration.json"
+
+func readColorCalibrations(path string) (map[EV3Port]*ColorCalibration, error) {
+	calibrations := map[EV3Port]*ColorCalibration{}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &calibrations); err != nil {
+		return nil, err
+	}
+	return calibrations, nil
+}
+
+// SaveColorCalibrations saves the calibration of each sensor under its port, keeping calibrations for other ports.
+func SaveColorCalibrations(path string, sensors ...*ColorSensor) error {
+	calibrations, err := readColorCalibrations(path)
+	if errors.Is(err, os.ErrNotExist) {
+		calibrations = map[EV3Port]*ColorCalibration{}
+	} else if err != nil {
+		return err
+	}
+
+	for _, s := range sensors {
+		if s.Port() == "" {
+			return fmt.Errorf("cannot save calibration of color sensor without a port")
+		}
+		if s.calibration != nil {
+			calibrations[s.Port()] = s.calibration
+		}
+	}
+
+	b, err := json.MarshalIndent(calibrations, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// LoadColorCalibrations loads the calibration saved for each sensor's port, leaving sensors without one unchanged.
+// If the file does not exist the error satisfies errors.Is(err, os.ErrNotExist), which can be ignored at startup.
+func LoadColorCalibrations(path string, sensors ...*ColorSensor) error {
+	calibrations, err := readColorCalibrations(path)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sensors {
+		if cal, found := calibrations[s.Port()]; found && s.Port() != "" {
+			s.calibration = cal
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Color Calibration Command                                                  //
+////////////////////////////////////////////////////////////////////////////////
+
+type colorCalibrationCommand struct {
+	DefaultCommand
+
+	brick   *EV3Brick
+	path    string
+	colors  []string
+	sensors []*ColorSensor
+
+	step     int
+	previous []*ColorCalibration
+}
+
+func (c *colorCalibrationCommand) steps() int {
+	return 2 + len(c.colors)
+}
+
+func (c *colorCalibrationCommand) prompt() {
+	name := "black"
+	if c.step == 1 {
+		name = "white"
+	} else if c.step > 1 {
+		name = c.colors[c.step-2]
+	}
+
+	c.brick.PrintScreen("Calibrate", fmt.Sprintf("Place on %v", name), "Press right", fmt.Sprintf("%d/%d", c.step+1, c.steps()))
+}
+
+func (c *colorCalibrationCommand) Init() {
+	c.step = 0
+	c.previous = c.previous[:0]
+	for _, s := range c.sensors {
+		c.previous = append(c.previous, s.Calibration())
+		s.SetCalibration(NewColorCalibration())
+	}
+	c.prompt()
+}
+
+func (c *colorCalibrationCommand) Run() {
+	if !c.brick.IsButtonPressed(Right) {
+		return
+	}
+
+	for _, s := range c.sensors {
+		switch c.step {
+		case 0:
+			s.CalibrateBlack()
+		case 1:
+			s.CalibrateWhite()
+		default:
+			s.CalibrateColor(c.colors[c.step-2])
+		}
+	}
+
+	c.step++
+	if c.step < c.steps() {
+		c.prompt()
+	}
+}
+
+func (c *colorCalibrationCommand) End(interrupted bool) {
+	if interrupted {
+		for i, s := range c.sensors {
+			s.SetCalibration(c.previous[i])
+		}
+		c.brick.PrintScreen("Calibration", "cancelled")
+		return
+	}
+
+	if err := SaveColorCalibrations(c.path, c.sensors...); err != nil {
+		fmt.Printf("could not save color calibration: %v\n", err)
+		c.brick.PrintScreen("Calibration", "not saved")
+		return
+	}
+	c.brick.PrintScreen("Calibration", "saved")
+}
+
+func (c *colorCalibrationCommand) IsDone() bool {
+	return c.step >= c.steps()
+}
+
+// NewColorCalibrationCommand walks through calibrating sensors on the LCD, for use from the main menu.
+// Each sensor is sampled over black, white, then each named color when the right button is pressed,
+// and the calibrations are saved to the file when done.
+func NewColorCalibrationCommand(brick *EV3Brick, path string, colors []string, sensors ...*ColorSensor) *Command {
+	return NewCommand(&colorCalibrationCommand{brick: brick, path: path, colors: colors, sensors: sensors})
+}
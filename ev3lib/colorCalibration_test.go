@@ -0,0 +1,177 @@
+package ev3lib_test
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/Alanlu217/ev3lib/ev3lib/testUtils"
+)
+
+// fixedColorSensor returns readings set by the test, from a port.
+type fixedColorSensor struct {
+	port       ev3lib.EV3Port
+	reflection float64
+	rgb        [3]float64
+}
+
+func (s *fixedColorSensor) Port() ev3lib.EV3Port {
+	return s.port
+}
+
+func (s *fixedColorSensor) Ambient() float64 {
+	return 0
+}
+
+func (s *fixedColorSensor) Reflection() float64 {
+	return s.reflection
+}
+
+func (s *fixedColorSensor) GetRGB() (float64, float64, float64) {
+	return s.rgb[0], s.rgb[1], s.rgb[2]
+}
+
+func TestColorCalibrationNormalize(t *testing.T) {
+	cal := &ev3lib.ColorCalibration{Black: 0.1, White: 0.7, BlackRGB: [3]float64{0.1, 0.2, 0.3}, WhiteRGB: [3]float64{0.5, 0.6, 0.9}}
+
+	for _, test := range []struct {
+		raw, want float64
+	}{
+		{0.1, 0},
+		{0.7, 1},
+		{0.4, 0.5},
+		{0, 0},
+		{0.9, 1},
+	} {
+		if got := cal.NormalizeReflection(test.raw); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("NormalizeReflection(%v) = %v, want %v", test.raw, got, test.want)
+		}
+	}
+
+	r, g, b := cal.NormalizeRGB(0.3, 0.2, 0.75)
+	if math.Abs(r-0.5) > 1e-9 || math.Abs(g) > 1e-9 || math.Abs(b-0.75) > 1e-9 {
+		t.Errorf("NormalizeRGB = %v, %v, %v, want 0.5, 0, 0.75", r, g, b)
+	}
+
+	// A calibration without a range leaves readings unchanged
+	if got := ev3lib.NewColorCalibration().NormalizeReflection(0.3); got != 0.3 {
+		t.Errorf("default calibration NormalizeReflection(0.3) = %v, want 0.3", got)
+	}
+	if got := (&ev3lib.ColorCalibration{Black: 0.5, White: 0.5}).NormalizeReflection(0.3); got != 0.3 {
+		t.Errorf("empty range NormalizeReflection(0.3) = %v, want 0.3", got)
+	}
+}
+
+func TestColorSensorCalibrate(t *testing.T) {
+	raw := &fixedColorSensor{port: ev3lib.IN1}
+	s := ev3lib.NewColorSensorBase(raw)
+
+	raw.reflection, raw.rgb = 0.1, [3]float64{0.1, 0.1, 0.1}
+	s.CalibrateBlack()
+	raw.reflection, raw.rgb = 0.7, [3]float64{0.5, 0.9, 0.7}
+	s.CalibrateWhite()
+
+	raw.reflection, raw.rgb = 0.4, [3]float64{0.3, 0.5, 0.1}
+	if got := s.Reflection(); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("Reflection = %v, want 0.5", got)
+	}
+	if got := s.RawReflection(); got != 0.4 {
+		t.Errorf("RawReflection = %v, want 0.4", got)
+	}
+	if r, g, b := s.GetRGB(); math.Abs(r-0.5) > 1e-9 || math.Abs(g-0.5) > 1e-9 || math.Abs(b) > 1e-9 {
+		t.Errorf("GetRGB = %v, %v, %v, want 0.5, 0.5, 0", r, g, b)
+	}
+
+	// Named colors are stored normalized
+	s.CalibrateColor("mat green")
+	if got, want := s.Calibration().Colors["mat green"], [3]float64{0.5, 0.5, 0}; math.Abs(got[0]-want[0])+math.Abs(got[1]-want[1])+math.Abs(got[2]-want[2]) > 1e-9 {
+		t.Errorf("mat green = %v, want %v", got, want)
+	}
+
+	s.ClearCalibration()
+	if got := s.Reflection(); got != 0.4 {
+		t.Errorf("Reflection after clearing = %v, want the raw 0.4", got)
+	}
+}
+
+func TestColorSensorCalibrateOnMat(t *testing.T) {
+	useManualClock(t)
+
+	// Dark, mid and light grey stripes 100mm wide, like a worn mat where black and white aren't 0 and 1
+	img := image.NewGray(image.Rect(0, 0, 3, 1))
+	for x, shade := range []uint8{51, 128, 204} {
+		img.SetGray(x, 0, color.Gray{Y: shade})
+	}
+	w := testUtils.NewSimWorld(testUtils.SimRobotConfig{WheelDiameter: 56, WheelBase: 120})
+	w.SetMat(img, 300, 100)
+	s := w.NewColorSensor(0, 0)
+
+	w.SetPose(50, 50, 0)
+	s.CalibrateBlack()
+	w.SetPose(250, 50, 0)
+	s.CalibrateWhite()
+
+	for _, test := range []struct {
+		x, want float64
+	}{
+		{50, 0},
+		{150, (128.0 - 51) / (204 - 51)},
+		{250, 1},
+	} {
+		w.SetPose(test.x, 50, 0)
+		if got := s.Reflection(); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("Reflection at x = %v is %v, want %v", test.x, got, test.want)
+		}
+	}
+}
+
+func TestColorCalibrationSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ev3lib.DefaultColorCalibrationFile)
+
+	if err := ev3lib.LoadColorCalibrations(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("loading a missing file = %v, want os.ErrNotExist", err)
+	}
+
+	in1 := ev3lib.NewColorSensorBase(&fixedColorSensor{port: ev3lib.IN1})
+	in1.SetCalibration(&ev3lib.ColorCalibration{Black: 0.05, White: 0.8, WhiteRGB: [3]float64{0.7, 0.8, 0.9}, Colors: map[string][3]float64{"red": {1, 0.1, 0}}})
+	in2 := ev3lib.NewColorSensorBase(&fixedColorSensor{port: ev3lib.IN2})
+	in2.SetCalibration(&ev3lib.ColorCalibration{Black: 0.1, White: 0.6, WhiteRGB: [3]float64{1, 1, 1}, Colors: map[string][3]float64{}})
+
+	// Saving each sensor separately keeps the other's calibration
+	if err := ev3lib.SaveColorCalibrations(path, in1); err != nil {
+		t.Fatal(err)
+	}
+	if err := ev3lib.SaveColorCalibrations(path, in2); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded1 := ev3lib.NewColorSensorBase(&fixedColorSensor{port: ev3lib.IN1})
+	loaded2 := ev3lib.NewColorSensorBase(&fixedColorSensor{port: ev3lib.IN2})
+	loaded3 := ev3lib.NewColorSensorBase(&fixedColorSensor{port: ev3lib.IN3})
+	if err := ev3lib.LoadColorCalibrations(path, loaded1, loaded2, loaded3); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(loaded1.Calibration(), in1.Calibration()) {
+		t.Errorf("IN1 loaded %+v, want %+v", loaded1.Calibration(), in1.Calibration())
+	}
+	// Empty color maps are omitted from the file
+	want2 := *in2.Calibration()
+	want2.Colors = nil
+	if !reflect.DeepEqual(loaded2.Calibration(), &want2) {
+		t.Errorf("IN2 loaded %+v, want %+v", loaded2.Calibration(), &want2)
+	}
+	if loaded3.Calibration() != nil {
+		t.Errorf("IN3 loaded %+v, want no calibration", loaded3.Calibration())
+	}
+
+	if err := ev3lib.SaveColorCalibrations(path, ev3lib.NewColorSensorBase(&fixedColorSensor{})); err == nil {
+		t.Error("saving a sensor without a port succeeded, want an error")
+	}
+}
@@ -0,0 +1,179 @@
+package ev3lib
+
+import "math"
+
+////////////////////////////////////////////////////////////////////////////////
+// Color Spaces                                                               //
+////////////////////////////////////////////////////////////////////////////////
+
+// ColorSpace is the space distances between colors are measured in.
+type ColorSpace int
+
+const (
+	// RGBSpace compares red, green and blue directly.
+	RGBSpace ColorSpace = iota
+
+	// HSVSpace compares hue, saturation and value, which is less affected by changes in brightness.
+	HSVSpace
+)
+
+// RGBToHSV converts a color with channels from 0 to 1 to a hue in degrees from 0 to 360, and saturation and value from 0 to 1.
+func RGBToHSV(r, g, b float64) (h, s, v float64) {
+	v = max(r, g, b)
+	c := v - min(r, g, b)
+
+	if v > 0 {
+		s = c / v
+	}
+
+	if c > 0 {
+		switch v {
+		case r:
+			h = 60 * math.Mod((g-b)/c, 6)
+		case g:
+			h = 60 * ((b-r)/c + 2)
+		default:
+			h = 60 * ((r-g)/c + 4)
+		}
+	}
+	if h < 0 {
+		h += 360
+	}
+
+	return h, s, v
+}
+
+// colorPoint returns the coordinates of a color in a space where distances can be compared.
+// HSV colors are placed on a cone so hues wrap around and grey colors are close whatever their hue.
+func colorPoint(space ColorSpace, r, g, b float64) [3]float64 {
+	if space == HSVSpace {
+		h, s, v := RGBToHSV(r, g, b)
+		h *= math.Pi / 180
+		return [3]float64{s * v * math.Cos(h), s * v * math.Sin(h), v}
+	}
+	return [3]float64{r, g, b}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Color Classifier                                                           //
+////////////////////////////////////////////////////////////////////////////////
+
+type colorSample struct {
+	name  string
+	point [3]float64
+}
+
+// ColorClassifier names colors by finding the nearest sample.
+type ColorClassifier struct {
+	space       ColorSpace
+	maxDistance float64
+
+	samples []colorSample
+}
+
+// NewColorClassifier creates a classifier with no samples and no maximum distance.
+func NewColorClassifier(space ColorSpace) *ColorClassifier {
+	return &ColorClassifier{space: space, maxDistance: math.Inf(1)}
+}
+
+// NewColorClassifierFromCalibration creates a classifier from the named colors of a calibration.
+// Use it with readings from the calibrated sensor, which are normalized the same way.
+func NewColorClassifierFromCalibration(calibration *ColorCalibration, space ColorSpace) *ColorClassifier {
+	c := NewColorClassifier(space)
+	for name, rgb := range calibration.Colors {
+		c.AddSample(name, rgb[0], rgb[1], rgb[2])
+	}
+	return c
+}
+
+// AddSample adds a reading of a named color. A color may have several samples, e.g. from different parts of the mat.
+func (c *ColorClassifier) AddSample(name string, r, g, b float64) *ColorClassifier {
+	c.samples = append(c.samples, colorSample{name: name, point: colorPoint(c.space, r, g, b)})
+	return c
+}
+
+// SetMaxDistance sets how far a reading can be from its nearest sample, beyond which it is not classified.
+func (c *ColorClassifier) SetMaxDistance(distance float64) *ColorClassifier {
+	c.maxDistance = distance
+	return c
+}
+
+// Classify returns the name of the nearest sample to a color, and a confidence from 0 to 1.
+// Confidence is low when the color is almost as close to a differently named sample, or near the max distance.
+// An empty name is returned if there are no samples or the color is too far from all of them.
+func (c *ColorClassifier) Classify(r, g, b float64) (string, float64) {
+	p := colorPoint(c.space, r, g, b)
+
+	dist := func(s colorSample) float64 {
+		return math.Sqrt((p[0]-s.point[0])*(p[0]-s.point[0]) + (p[1]-s.point[1])*(p[1]-s.point[1]) + (p[2]-s.point[2])*(p[2]-s.point[2]))
+	}
+
+	name, nearest := "", math.Inf(1)
+	for _, s := range c.samples {
+		if d := dist(s); d < nearest {
+			name, nearest = s.name, d
+		}
+	}
+	if name == "" || nearest > c.maxDistance {
+		return "", 0
+	}
+
+	// The nearest sample of any other color
+	other := math.Inf(1)
+	for _, s := range c.samples {
+		if s.name != name {
+			other = min(other, dist(s))
+		}
+	}
+
+	confidence := 1.0
+	if !math.IsInf(other, 1) && other+nearest > 0 {
+		confidence = (other - nearest) / (other + nearest)
+	}
+	if !math.IsInf(c.maxDistance, 1) && c.maxDistance > 0 {
+		confidence *= 1 - nearest/c.maxDistance
+	}
+
+	return name, confidence
+}
+
+// ClassifyColor classifies the sensor's current, calibrated if available, reading.
+func (c *ColorSensor) ClassifyColor(classifier *ColorClassifier) (string, float64) {
+	return classifier.Classify(c.GetRGB())
+}
+
+// Color returns the LEGO color detected by the sensor if it implements ColorDetectorInterface,
+// otherwise the nearest LEGO color to its calibrated reading.
+func (c *ColorSensor) Color() LegoColor {
+	if d, ok := c.ColorSensorInterface.(ColorDetectorInterface); ok {
+		return d.Color()
+	}
+	color, _ := ClassifyLegoColor(c.GetRGB())
+	return color
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Lego Colors                                                                //
+////////////////////////////////////////////////////////////////////////////////
+
+var legoColorClassifier = NewColorClassifier(HSVSpace).
+	AddSample(BlackColor.String(), 0.05, 0.05, 0.05).
+	AddSample(BlueColor.String(), 0.1, 0.2, 0.6).
+	AddSample(GreenColor.String(), 0.1, 0.5, 0.2).
+	AddSample(YellowColor.String(), 0.9, 0.8, 0.1).
+	AddSample(RedColor.String(), 0.8, 0.1, 0.1).
+	AddSample(WhiteColor.String(), 0.9, 0.9, 0.9).
+	AddSample(BrownColor.String(), 0.4, 0.25, 0.1)
+
+// ClassifyLegoColor returns the LEGO color nearest to a color with channels from 0 to 1, and a confidence from 0 to 1.
+// It works best with readings from a calibrated sensor, where white is close to 1.
+func ClassifyLegoColor(r, g, b float64) (LegoColor, float64) {
+	name, confidence := legoColorClassifier.Classify(r, g, b)
+
+	for c := BlackColor; c <= BrownColor; c++ {
+		if c.String() == name {
+			return c, confidence
+		}
+	}
+	return NoColor, 0
+}
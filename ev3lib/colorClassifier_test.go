@@ -0,0 +1,171 @@
+package ev3lib_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+func TestRGBToHSV(t *testing.T) {
+	for _, test := range []struct {
+		r, g, b float64
+		h, s, v float64
+	}{
+		{1, 0, 0, 0, 1, 1},
+		{0, 1, 0, 120, 1, 1},
+		{0, 0, 1, 240, 1, 1},
+		{1, 0, 1, 300, 1, 1},
+		{1, 1, 0, 60, 1, 1},
+		{0.5, 0.25, 0.25, 0, 0.5, 0.5},
+		{0.5, 0.5, 0.5, 0, 0, 0.5},
+		{0, 0, 0, 0, 0, 0},
+	} {
+		h, s, v := ev3lib.RGBToHSV(test.r, test.g, test.b)
+		if math.Abs(h-test.h) > 1e-9 || math.Abs(s-test.s) > 1e-9 || math.Abs(v-test.v) > 1e-9 {
+			t.Errorf("RGBToHSV(%v, %v, %v) = %v, %v, %v, want %v, %v, %v", test.r, test.g, test.b, h, s, v, test.h, test.s, test.v)
+		}
+	}
+}
+
+func TestColorClassifier(t *testing.T) {
+	rgb := func() *ev3lib.ColorClassifier {
+		return ev3lib.NewColorClassifier(ev3lib.RGBSpace).
+			AddSample("red", 1, 0, 0).
+			AddSample("green", 0, 1, 0).
+			AddSample("black", 0, 0, 0)
+	}
+
+	for _, test := range []struct {
+		name       string
+		classifier *ev3lib.ColorClassifier
+		r, g, b    float64
+
+		want string
+		// confidence is checked when not negative
+		confidence float64
+	}{
+		{"exact sample", rgb(), 1, 0, 0, "red", 1},
+		{"nearest sample", rgb(), 0.8, 0.2, 0.1, "red", -1},
+		{"no samples", ev3lib.NewColorClassifier(ev3lib.RGBSpace), 1, 0, 0, "", 0},
+		{"beyond max distance", rgb().SetMaxDistance(0.1), 0.5, 0.2, 0, "", 0},
+		{"within max distance", rgb().SetMaxDistance(0.5), 1, 0, 0, "red", 1},
+		{"one name", ev3lib.NewColorClassifier(ev3lib.RGBSpace).AddSample("mat", 0.5, 0.5, 0.5), 0, 0, 0, "mat", 1},
+		// Dark red is nearer black in RGB, but has the hue of red in HSV
+		{"dark red in RGB", rgb(), 0.3, 0, 0, "black", -1},
+		{"dark red in HSV", ev3lib.NewColorClassifier(ev3lib.HSVSpace).
+			AddSample("red", 1, 0, 0).
+			AddSample("black", 0, 0, 0), 0.6, 0, 0, "red", -1},
+		// Hues wrap around, so a pinkish red is nearer red at 0 degrees than blue at 240
+		{"hue wraps", ev3lib.NewColorClassifier(ev3lib.HSVSpace).
+			AddSample("red", 1, 0, 0).
+			AddSample("blue", 0, 0, 1), 1, 0, 0.3, "red", -1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			name, confidence := test.classifier.Classify(test.r, test.g, test.b)
+
+			if name != test.want {
+				t.Errorf("Classify = %q, want %q", name, test.want)
+			}
+			if test.confidence >= 0 && math.Abs(confidence-test.confidence) > 1e-9 {
+				t.Errorf("confidence = %v, want %v", confidence, test.confidence)
+			}
+			if confidence < 0 || confidence > 1 {
+				t.Errorf("confidence = %v, want from 0 to 1", confidence)
+			}
+		})
+	}
+}
+
+func TestColorClassifierConfidence(t *testing.T) {
+	c := ev3lib.NewColorClassifier(ev3lib.RGBSpace).
+		AddSample("red", 1, 0, 0).
+		AddSample("green", 0, 1, 0)
+
+	// Confidence falls as a reading moves towards the other color
+	last := 2.0
+	for _, g := range []float64{0, 0.2, 0.4, 0.5} {
+		_, confidence := c.Classify(1-g, g, 0)
+		if confidence >= last {
+			t.Errorf("confidence at green %v = %v, want less than %v", g, confidence, last)
+		}
+		last = confidence
+	}
+	if last != 0 {
+		t.Errorf("confidence halfway between colors = %v, want 0", last)
+	}
+
+	// Another sample of the same color doesn't lower the confidence
+	c.AddSample("red", 0.9, 0.1, 0)
+	if name, confidence := c.Classify(1, 0, 0); name != "red" || confidence != 1 {
+		t.Errorf("Classify = %q, %v, want red with a confidence of 1", name, confidence)
+	}
+}
+
+func TestColorClassifierFromCalibration(t *testing.T) {
+	cal := ev3lib.NewColorCalibration()
+	cal.Colors["mat blue"] = [3]float64{0.1, 0.3, 0.8}
+	cal.Colors["mat yellow"] = [3]float64{0.9, 0.9, 0.2}
+
+	c := ev3lib.NewColorClassifierFromCalibration(cal, ev3lib.HSVSpace)
+	for _, test := range []struct {
+		r, g, b float64
+		want    string
+	}{
+		{0.15, 0.3, 0.7, "mat blue"},
+		{0.8, 0.85, 0.3, "mat yellow"},
+	} {
+		if name, _ := c.Classify(test.r, test.g, test.b); name != test.want {
+			t.Errorf("Classify(%v, %v, %v) = %q, want %q", test.r, test.g, test.b, name, test.want)
+		}
+	}
+}
+
+func TestClassifyLegoColor(t *testing.T) {
+	for _, test := range []struct {
+		r, g, b float64
+		want    ev3lib.LegoColor
+	}{
+		{0.02, 0.03, 0.02, ev3lib.BlackColor},
+		{0.1, 0.25, 0.7, ev3lib.BlueColor},
+		{0.15, 0.55, 0.25, ev3lib.GreenColor},
+		{0.95, 0.85, 0.15, ev3lib.YellowColor},
+		{0.85, 0.15, 0.1, ev3lib.RedColor},
+		{0.95, 0.95, 0.92, ev3lib.WhiteColor},
+		{0.45, 0.3, 0.12, ev3lib.BrownColor},
+	} {
+		if got, _ := ev3lib.ClassifyLegoColor(test.r, test.g, test.b); got != test.want {
+			t.Errorf("ClassifyLegoColor(%v, %v, %v) = %v, want %v", test.r, test.g, test.b, got, test.want)
+		}
+	}
+}
+
+// detectingColorSensor is a fixedColorSensor which also detects colors itself.
+type detectingColorSensor struct {
+	fixedColorSensor
+	color ev3lib.LegoColor
+}
+
+func (s *detectingColorSensor) Color() ev3lib.LegoColor {
+	return s.color
+}
+
+func TestColorSensorColor(t *testing.T) {
+	// Sensors which detect colors are used directly
+	detecting := ev3lib.NewColorSensorBase(&detectingColorSensor{fixedColorSensor: fixedColorSensor{rgb: [3]float64{1, 0, 0}}, color: ev3lib.BlueColor})
+	if got := detecting.Color(); got != ev3lib.BlueColor {
+		t.Errorf("detecting sensor Color = %v, want %v", got, ev3lib.BlueColor)
+	}
+
+	// Other sensors classify their calibrated reading, which is dark red before calibration
+	raw := &fixedColorSensor{rgb: [3]float64{0.2, 0.05, 0.05}}
+	s := ev3lib.NewColorSensorBase(raw)
+	if got := s.Color(); got != ev3lib.BlackColor {
+		t.Errorf("uncalibrated Color = %v, want %v", got, ev3lib.BlackColor)
+	}
+
+	s.SetCalibration(&ev3lib.ColorCalibration{White: 0.25, WhiteRGB: [3]float64{0.25, 0.25, 0.25}})
+	if got := s.Color(); got != ev3lib.RedColor {
+		t.Errorf("calibrated Color = %v, want %v", got, ev3lib.RedColor)
+	}
+}
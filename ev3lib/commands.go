@@ -43,10 +43,56 @@ func (b *DefaultCommand) IsDone() bool {
 // Command provides decorator functions on commands.
 type Command struct {
 	CommandInterface
+
+	requirements []*Subsystem
+
+	uninterruptible bool
 }
 
 func NewCommand(c CommandInterface) *Command {
-	return &Command{c}
+	return &Command{CommandInterface: c}
+}
+
+// Requires adds subsystems that the command needs exclusive use of when run on a Scheduler.
+func (c *Command) Requires(subsystems ...*Subsystem) *Command {
+	for _, s := range subsystems {
+		if !slices.Contains(c.requirements, s) {
+			c.requirements = append(c.requirements, s)
+		}
+	}
+	return c
+}
+
+// Requirements returns the subsystems required by the command.
+func (c *Command) Requirements() []*Subsystem {
+	return c.requirements
+}
+
+// Uninterruptible stops a Scheduler interrupting the command to run another which needs the same subsystems.
+// The other command is not scheduled instead. Cancel still ends the command.
+func (c *Command) Uninterruptible() *Command {
+	c.uninterruptible = true
+	return c
+}
+
+// IsInterruptible returns whether a Scheduler may interrupt the command to run another.
+func (c *Command) IsInterruptible() bool {
+	return !c.uninterruptible
+}
+
+// requirementsOf collects the requirements of all commands which declare any.
+func requirementsOf(commands ...CommandInterface) []*Subsystem {
+	result := make([]*Subsystem, 0)
+	for _, c := range commands {
+		if r, ok := c.(interface{ Requirements() []*Subsystem }); ok {
+			for _, s := range r.Requirements() {
+				if !slices.Contains(result, s) {
+					result = append(result, s)
+				}
+			}
+		}
+	}
+	return result
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -55,7 +101,7 @@ func NewCommand(c CommandInterface) *Command {
 
 // WithTimeout adds a timeout to a command specified by `dur`.
 func (c *Command) WithTimeout(dur time.Duration) *Command {
-	return NewCommand(NewParallelRace(c.CommandInterface, NewWaitCommand(dur)))
+	return NewParallelRace(c, NewWaitCommand(dur))
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -69,7 +115,7 @@ type untilCommandDecorator struct {
 
 // Until will run a command but will interrupt if a predicate is satisfied.
 func (c *Command) Until(predicate func() bool) *Command {
-	return NewCommand(&untilCommandDecorator{c: c.CommandInterface, p: predicate})
+	return NewCommand(&untilCommandDecorator{c: c.CommandInterface, p: predicate}).Requires(c.requirements...)
 }
 
 func (u *untilCommandDecorator) Init() {
@@ -101,25 +147,25 @@ func (u *untilCommandDecorator) IsDone() bool {
 
 // OnlyIf will run a command only if a predicate returns true.
 func (c *Command) OnlyIf(pred func() bool) *Command {
-	return NewIfCommand(pred, c.CommandInterface, NewFuncCommand(func() {}))
+	return NewIfCommand(pred, c, NewFuncCommand(func() {}))
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 
 func (c *Command) Then(cc ...CommandInterface) *Command {
-	return NewSequence(slices.Insert(cc, 0, c.CommandInterface)...)
+	return NewSequence(slices.Insert(cc, 0, CommandInterface(c))...)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 
 func (c *Command) While(cc ...CommandInterface) *Command {
-	return NewParallel(append(cc, c.CommandInterface)...)
+	return NewParallel(append(cc, c)...)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 
 func (c *Command) RaceWith(cc ...CommandInterface) *Command {
-	return NewParallelRace(append(cc, c.CommandInterface)...)
+	return NewParallelRace(append(cc, c)...)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -130,7 +176,7 @@ type repeatCommandDecorator struct {
 
 // Repeatedly will run a command forever reinitialising it if it ends.
 func (c *Command) Repeatedly() *Command {
-	return NewCommand(&repeatCommandDecorator{c: c.CommandInterface})
+	return NewCommand(&repeatCommandDecorator{c: c.CommandInterface}).Requires(c.requirements...)
 }
 
 func (r *repeatCommandDecorator) Init() {
@@ -163,7 +209,7 @@ type whenDoneCommandDecorator struct {
 
 // WhenDone will run a command then a function when it's done.
 func (c *Command) WhenDone(f func(bool)) *Command {
-	return NewCommand(&whenDoneCommandDecorator{c: c.CommandInterface, f: f})
+	return NewCommand(&whenDoneCommandDecorator{c: c.CommandInterface, f: f}).Requires(c.requirements...)
 }
 
 func (r *whenDoneCommandDecorator) Init() {
@@ -183,40 +229,84 @@ func (r *whenDoneCommandDecorator) IsDone() bool {
 	return r.c.IsDone()
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// Periodic Functions                                                         //
+////////////////////////////////////////////////////////////////////////////////
+
+var periodics = make([]func(), 0)
+
+// AddPeriodic registers a function to be called once every loop of RunCommand, RunTimedCommand and the main menu.
+// Schedulers also call them, unless they are being run inside one of those loops.
+// Used for background work such as updating odometry.
+func AddPeriodic(f func()) {
+	periodics = append(periodics, f)
+}
+
+// ClearPeriodics removes all registered periodic functions.
+func ClearPeriodics() {
+	periodics = make([]func(), 0)
+}
+
+func runPeriodics() {
+	for _, f := range periodics {
+		f()
+	}
+}
+
+// runnerDepth counts the blocking runners currently running a command, so periodic functions are only called by the outermost one.
+var runnerDepth int
+
 ////////////////////////////////////////////////////////////////////////////////
 // Blocking CommandInterface Runner                                           //
 ////////////////////////////////////////////////////////////////////////////////
 
 // RunCommand will run a command in a blocking fashion.
 func RunCommand(c CommandInterface) {
-	c.Init()
-	for !c.IsDone() {
-		c.Run()
-	}
-	c.End(false)
+	runLoop(c, 0, nil)
 }
 
 // RunTimedCommand will run a command in a blocking fashion with a target interval time.
 func RunTimedCommand(c CommandInterface, intervalTime time.Duration) {
-	t := time.NewTicker(intervalTime)
+	runLoop(c, intervalTime, nil)
+}
+
+// runLoop runs a command until it is done or cancel returns true, calling periodic functions before every iteration.
+// An interval time of 0 runs iterations back to back. Returns whether the command was cancelled.
+func runLoop(c CommandInterface, intervalTime time.Duration, cancel func() bool) bool {
+	runnerDepth++
+	defer func() { runnerDepth-- }()
+
+	var t *intervalTimer
+	if intervalTime > 0 {
+		t = newIntervalTimer(intervalTime)
+	}
 
 	c.Init()
 	for !c.IsDone() {
-		start := time.Now()
+		if cancel != nil && cancel() {
+			c.End(true)
+			return true
+		}
+
+		start := Now()
 
+		if runnerDepth == 1 {
+			runPeriodics()
+		}
 		c.Run()
 
-		delta := time.Since(start)
+		if t != nil {
+			delta := Since(start)
 
-		if delta > intervalTime {
-			log.Printf("Loop time overrun, took: %v\n", delta)
-		}
+			if delta > intervalTime {
+				log.Printf("Loop time overrun, took: %v\n", delta)
+			}
 
-		<-t.C
+			t.wait()
+		}
 	}
 	c.End(false)
-
-	t.Stop()
+	return false
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -232,7 +322,7 @@ type sequence struct {
 
 // NewSequence will run several commands one after another.
 func NewSequence(commands ...CommandInterface) *Command {
-	return NewCommand(&sequence{current: 0, commands: commands})
+	return NewCommand(&sequence{current: 0, commands: commands}).Requires(requirementsOf(commands...)...)
 }
 
 func (s *sequence) Init() {
@@ -277,7 +367,7 @@ type parallel struct {
 
 // NewParallel will run several commands at the same time waiting for all commands to complete.
 func NewParallel(commands ...CommandInterface) *Command {
-	return NewCommand(&parallel{commands: commands, incomplete: len(commands)})
+	return NewCommand(&parallel{commands: commands, incomplete: len(commands)}).Requires(requirementsOf(commands...)...)
 }
 
 func (p *parallel) Init() {
@@ -323,7 +413,7 @@ type parallelRace struct {
 // NewParallelRace will run several commands at the same time.
 // It will finish when the first command finishes and will interrupt the rest.
 func NewParallelRace(commands ...CommandInterface) *Command {
-	return NewCommand(&parallelRace{commands: commands})
+	return NewCommand(&parallelRace{commands: commands}).Requires(requirementsOf(commands...)...)
 }
 
 func (p *parallelRace) Init() {
@@ -396,11 +486,11 @@ func NewWaitCommand(time time.Duration) *Command {
 }
 
 func (w *waitCommand) Init() {
-	w.init = time.Now()
+	w.init = Now()
 }
 
 func (w *waitCommand) IsDone() bool {
-	return time.Since(w.init) > w.dur
+	return Since(w.init) > w.dur
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -441,7 +531,7 @@ type ifCommand struct {
 // NewIfCommand returns a command that will run a command depending on a predicate.
 // If the predicate returns true when the command is initialised, then command a will be run.
 func NewIfCommand(runA func() bool, a, b CommandInterface) *Command {
-	return NewCommand(&ifCommand{isA: true, pred: runA, a: a, b: b})
+	return NewCommand(&ifCommand{isA: true, pred: runA, a: a, b: b}).Requires(requirementsOf(a, b)...)
 }
 
 func (f *ifCommand) Init() {
@@ -0,0 +1,88 @@
+package ev3lib_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+// countCommand calls run on every iteration and finishes after `n` iterations.
+type countCommand struct {
+	ev3lib.DefaultCommand
+
+	n, runs int
+	run     func()
+}
+
+func (c *countCommand) Init() {
+	c.runs = 0
+}
+
+func (c *countCommand) Run() {
+	c.runs++
+	if c.run != nil {
+		c.run()
+	}
+}
+
+func (c *countCommand) IsDone() bool {
+	return c.runs >= c.n
+}
+
+func countPeriodics(t *testing.T) *int {
+	calls := 0
+	ev3lib.ClearPeriodics()
+	ev3lib.AddPeriodic(func() { calls++ })
+	t.Cleanup(ev3lib.ClearPeriodics)
+
+	return &calls
+}
+
+func TestPeriodics(t *testing.T) {
+	useManualClock(t)
+
+	for _, test := range []struct {
+		name string
+		run  func(c ev3lib.CommandInterface)
+	}{
+		{"RunCommand", ev3lib.RunCommand},
+		{"RunTimedCommand", func(c ev3lib.CommandInterface) { ev3lib.RunTimedCommand(c, 20*time.Millisecond) }},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			calls := countPeriodics(t)
+
+			test.run(&countCommand{n: 5})
+			if *calls != 5 {
+				t.Errorf("periodics called %d times, want 5", *calls)
+			}
+
+			// A scheduler run inside the loop leaves the periodics to the runner
+			*calls = 0
+			s := ev3lib.NewScheduler()
+			test.run(&countCommand{n: 5, run: s.Run})
+			if *calls != 5 {
+				t.Errorf("periodics called %d times with a nested scheduler, want 5", *calls)
+			}
+
+			// Nested runners only call them once per outer iteration
+			*calls = 0
+			test.run(&countCommand{n: 2, run: func() { ev3lib.RunCommand(&countCommand{n: 3}) }})
+			if *calls != 2 {
+				t.Errorf("periodics called %d times with a nested runner, want 2", *calls)
+			}
+		})
+	}
+
+	t.Run("Scheduler", func(t *testing.T) {
+		calls := countPeriodics(t)
+
+		s := ev3lib.NewScheduler()
+		for i := 0; i < 3; i++ {
+			s.Run()
+		}
+		if *calls != 3 {
+			t.Errorf("periodics called %d times, want 3", *calls)
+		}
+	})
+}
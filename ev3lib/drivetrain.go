@@ -0,0 +1,354 @@
+package ev3lib
+
+import "math"
+
+////////////////////////////////////////////////////////////////////////////////
+// Differential Drive                                                         //
+////////////////////////////////////////////////////////////////////////////////
+
+// DifferentialDrive is a subsystem for a robot driven by a left and right wheel.
+// Distances are in the same unit as the wheel diameter and track width, usually millimetres.
+// Angles are in degrees and, like the EV3 gyro, positive angles turn clockwise.
+// Motor positions are expected to be in degrees, i.e. with a scale of 1.
+type DifferentialDrive struct {
+	*Subsystem
+
+	left, right MotorInterface
+	gyro        GyroSensorInterface
+
+	wheelDiameter, trackWidth float64
+
+	rampUp, rampDown, minSpeed float64
+	maxSpeed                   float64
+
+	headingPID *PIDController
+}
+
+// NewDifferentialDrive creates a drivetrain from two motors which both drive forwards with positive power.
+// The gyro is used to hold heading and measure turns, if it is nil the wheel positions are used instead.
+func NewDifferentialDrive(left, right MotorInterface, wheelDiameter, trackWidth float64, gyro GyroSensorInterface) *DifferentialDrive {
+	return &DifferentialDrive{
+		Subsystem:     NewSubsystem("drivetrain"),
+		left:          left,
+		right:         right,
+		gyro:          gyro,
+		wheelDiameter: wheelDiameter,
+		trackWidth:    trackWidth,
+		rampUp:        50,
+		rampDown:      100,
+		minSpeed:      0.1,
+		maxSpeed:      1050.0 / 360 * math.Pi * wheelDiameter,
+		headingPID:    NewPIDController(0.02, 0, 0),
+	}
+}
+
+func (d *DifferentialDrive) Left() MotorInterface {
+	return d.left
+}
+
+func (d *DifferentialDrive) Right() MotorInterface {
+	return d.right
+}
+
+func (d *DifferentialDrive) Gyro() GyroSensorInterface {
+	return d.gyro
+}
+
+func (d *DifferentialDrive) WheelDiameter() float64 {
+	return d.wheelDiameter
+}
+
+func (d *DifferentialDrive) TrackWidth() float64 {
+	return d.trackWidth
+}
+
+// SetRamp sets the distances over which commands speed up from and slow down to `minSpeed`.
+// Turns ramp over the distance travelled by the wheels. A distance of 0 disables that ramp.
+func (d *DifferentialDrive) SetRamp(rampUp, rampDown, minSpeed float64) {
+	d.rampUp = rampUp
+	d.rampDown = rampDown
+	d.minSpeed = minSpeed
+}
+
+// SetMaxSpeed sets the wheel speed reached at full power, in distance per second.
+// Defaults to the free speed of an EV3 large motor, 1050 degrees per second.
+func (d *DifferentialDrive) SetMaxSpeed(maxSpeed float64) {
+	d.maxSpeed = maxSpeed
+}
+
+func (d *DifferentialDrive) MaxSpeed() float64 {
+	return d.maxSpeed
+}
+
+// SetHeadingPID sets the controller used to hold heading while driving, in power per degree of error.
+func (d *DifferentialDrive) SetHeadingPID(pid *PIDController) {
+	d.headingPID = pid
+}
+
+// LeftDistance returns the distance travelled by the left wheel.
+func (d *DifferentialDrive) LeftDistance() float64 {
+	return d.left.Position() / 360 * math.Pi * d.wheelDiameter
+}
+
+// RightDistance returns the distance travelled by the right wheel.
+func (d *DifferentialDrive) RightDistance() float64 {
+	return d.right.Position() / 360 * math.Pi * d.wheelDiameter
+}
+
+// Distance returns the average distance travelled by both wheels.
+func (d *DifferentialDrive) Distance() float64 {
+	return (d.LeftDistance() + d.RightDistance()) / 2
+}
+
+// Heading returns the gyro angle, or the angle estimated from the wheels if there is no gyro.
+func (d *DifferentialDrive) Heading() float64 {
+	if d.gyro != nil {
+		return d.gyro.Angle()
+	}
+	return (d.LeftDistance() - d.RightDistance()) / d.trackWidth * 180 / math.Pi
+}
+
+// SetTank sets the power of each side directly.
+func (d *DifferentialDrive) SetTank(left, right float64) {
+	d.left.Set(Clamp(left, -1, 1))
+	d.right.Set(Clamp(right, -1, 1))
+}
+
+// SetArcade drives with a forward power and a turning power, positive turns clockwise.
+// Outputs are scaled down so neither side exceeds full power.
+func (d *DifferentialDrive) SetArcade(forward, turn float64) {
+	left, right := forward+turn, forward-turn
+
+	if m := max(math.Abs(left), math.Abs(right)); m > 1 {
+		left, right = left/m, right/m
+	}
+
+	d.SetTank(left, right)
+}
+
+// SetWheelSpeeds drives each side at a speed in distance per second, scaled by the max speed.
+func (d *DifferentialDrive) SetWheelSpeeds(left, right float64) {
+	d.SetTank(left/d.maxSpeed, right/d.maxSpeed)
+}
+
+// Stop stops both motors.
+func (d *DifferentialDrive) Stop() {
+	d.left.Stop()
+	d.right.Stop()
+}
+
+// rampedSpeed limits speed near the start and end of a move.
+func (d *DifferentialDrive) rampedSpeed(speed, travelled, remaining float64) float64 {
+	s := speed
+	if d.rampUp > 0 {
+		s = min(s, d.minSpeed+(speed-d.minSpeed)*math.Max(travelled, 0)/d.rampUp)
+	}
+	if d.rampDown > 0 {
+		s = min(s, d.minSpeed+(speed-d.minSpeed)*math.Max(remaining, 0)/d.rampDown)
+	}
+	return max(s, min(d.minSpeed, speed))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Differential Drive Commands                                                //
+////////////////////////////////////////////////////////////////////////////////
+
+type tankCommand struct {
+	DefaultCommand
+
+	left, right float64
+	d           *DifferentialDrive
+}
+
+func (t *tankCommand) Run() {
+	t.d.SetTank(t.left, t.right)
+}
+
+func (t *tankCommand) End(bool) {
+	t.d.Stop()
+}
+
+func (t *tankCommand) IsDone() bool {
+	return false
+}
+
+// Tank drives each side at a fixed power until interrupted.
+func (d *DifferentialDrive) Tank(left, right float64) *Command {
+	return NewCommand(&tankCommand{left: left, right: right, d: d}).Requires(d.Subsystem)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type arcadeCommand struct {
+	DefaultCommand
+
+	forward, turn float64
+	d             *DifferentialDrive
+}
+
+func (a *arcadeCommand) Run() {
+	a.d.SetArcade(a.forward, a.turn)
+}
+
+func (a *arcadeCommand) End(bool) {
+	a.d.Stop()
+}
+
+func (a *arcadeCommand) IsDone() bool {
+	return false
+}
+
+// Arcade drives with a fixed forward and turning power until interrupted, positive turns clockwise.
+func (d *DifferentialDrive) Arcade(forward, turn float64) *Command {
+	return NewCommand(&arcadeCommand{forward: forward, turn: turn, d: d}).Requires(d.Subsystem)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type driveStraightCommand struct {
+	DefaultCommand
+
+	distance, speed float64
+
+	startDistance, heading float64
+	travelled              float64
+
+	d *DifferentialDrive
+}
+
+func (c *driveStraightCommand) Init() {
+	c.startDistance = c.d.Distance()
+	c.heading = c.d.Heading()
+	c.travelled = 0
+	c.d.headingPID.Reset()
+}
+
+func (c *driveStraightCommand) Run() {
+	c.travelled = math.Abs(c.d.Distance() - c.startDistance)
+	speed := c.d.rampedSpeed(c.speed, c.travelled, math.Abs(c.distance)-c.travelled)
+
+	if c.distance < 0 {
+		speed = -speed
+	}
+
+	correction := c.d.headingPID.Get(c.d.Heading(), c.heading)
+	c.d.SetTank(speed+correction, speed-correction)
+}
+
+func (c *driveStraightCommand) End(bool) {
+	c.d.Stop()
+}
+
+func (c *driveStraightCommand) IsDone() bool {
+	return c.travelled >= math.Abs(c.distance)
+}
+
+// DriveStraight drives a distance at a speed from 0 to 1, holding the heading from when the command starts.
+// Negative distances drive backwards.
+func (d *DifferentialDrive) DriveStraight(distance, speed float64) *Command {
+	return NewCommand(&driveStraightCommand{distance: distance, speed: math.Abs(speed), d: d}).Requires(d.Subsystem)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type turnInPlaceCommand struct {
+	DefaultCommand
+
+	angle, speed float64
+
+	startHeading float64
+	turned       float64
+
+	d *DifferentialDrive
+}
+
+func (c *turnInPlaceCommand) Init() {
+	c.startHeading = c.d.Heading()
+	c.turned = 0
+}
+
+func (c *turnInPlaceCommand) Run() {
+	c.turned = math.Abs(c.d.Heading() - c.startHeading)
+
+	// Ramp over the distance travelled by the wheels
+	wheelTravel := math.Pi * c.d.trackWidth / 360
+	speed := c.d.rampedSpeed(c.speed, c.turned*wheelTravel, (math.Abs(c.angle)-c.turned)*wheelTravel)
+
+	if c.angle < 0 {
+		speed = -speed
+	}
+
+	c.d.SetTank(speed, -speed)
+}
+
+func (c *turnInPlaceCommand) End(bool) {
+	c.d.Stop()
+}
+
+func (c *turnInPlaceCommand) IsDone() bool {
+	return c.turned >= math.Abs(c.angle)
+}
+
+// TurnInPlace turns on the spot by an angle at a speed from 0 to 1, positive angles turn clockwise.
+func (d *DifferentialDrive) TurnInPlace(angle, speed float64) *Command {
+	return NewCommand(&turnInPlaceCommand{angle: angle, speed: math.Abs(speed), d: d}).Requires(d.Subsystem)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type arcCommand struct {
+	DefaultCommand
+
+	radius, angle, speed float64
+
+	startDistance, startHeading float64
+	travelled                   float64
+
+	d *DifferentialDrive
+}
+
+func (c *arcCommand) Init() {
+	c.startDistance = c.d.Distance()
+	c.startHeading = c.d.Heading()
+	c.travelled = 0
+	c.d.headingPID.Reset()
+}
+
+func (c *arcCommand) length() float64 {
+	return math.Abs(c.radius * c.angle * math.Pi / 180)
+}
+
+func (c *arcCommand) Run() {
+	c.travelled = math.Abs(c.d.Distance() - c.startDistance)
+	speed := c.d.rampedSpeed(c.speed, c.travelled, c.length()-c.travelled)
+
+	// Outer wheel travels further than the inner wheel
+	outer := c.radius + c.d.trackWidth/2
+	inner := c.radius - c.d.trackWidth/2
+
+	left, right := outer, inner
+	if c.angle < 0 {
+		left, right = inner, outer
+	}
+	scale := speed / max(math.Abs(left), math.Abs(right))
+
+	// Correct towards the heading expected for the distance travelled
+	expected := c.startHeading + math.Copysign(c.travelled/math.Abs(c.radius)*180/math.Pi, c.angle)
+	correction := c.d.headingPID.Get(c.d.Heading(), expected)
+
+	c.d.SetTank(left*scale+correction, right*scale-correction)
+}
+
+func (c *arcCommand) End(bool) {
+	c.d.Stop()
+}
+
+func (c *arcCommand) IsDone() bool {
+	return c.travelled >= c.length()
+}
+
+// Arc drives forwards around a circle of a radius, measured to the centre of the robot, until the heading has changed by an angle.
+// Positive angles turn clockwise.
+func (d *DifferentialDrive) Arc(radius, angle, speed float64) *Command {
+	return NewCommand(&arcCommand{radius: math.Abs(radius), angle: angle, speed: math.Abs(speed), d: d}).Requires(d.Subsystem)
+}
@@ -0,0 +1,167 @@
+package ev3lib_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/Alanlu217/ev3lib/ev3lib/testUtils"
+)
+
+func newSimDrive(t *testing.T, gyro bool) (*testUtils.ManualClock, *testUtils.SimWorld, *ev3lib.DifferentialDrive) {
+	c := useManualClock(t)
+	w := testUtils.NewSimWorld(testUtils.SimRobotConfig{
+		WheelDiameter: 56,
+		WheelBase:     120,
+		LeftModel:     testUtils.LargeMotorModel,
+		RightModel:    testUtils.LargeMotorModel,
+	})
+
+	var g ev3lib.GyroSensorInterface
+	if gyro {
+		g = w.NewGyroSensor()
+	}
+	return c, w, ev3lib.NewDifferentialDrive(w.LeftMotor(), w.RightMotor(), 56, 120, g)
+}
+
+// runSim is runFor which also updates the world every step, as the world only moves when its sensors are read.
+func runSim(t *testing.T, c *testUtils.ManualClock, w *testUtils.SimWorld, cmd ev3lib.CommandInterface, limit time.Duration) {
+	t.Helper()
+
+	cmd.Init()
+	for start := c.Elapsed(); !c.StepCommand(cmd, 10*time.Millisecond); w.Update() {
+		if c.Elapsed()-start > limit {
+			cmd.End(true)
+			t.Fatalf("command did not finish within %v", limit)
+		}
+	}
+	w.Update()
+}
+
+func TestDriveSigns(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		cmd     func(d *ev3lib.DifferentialDrive) *ev3lib.Command
+		forward float64
+		turn    float64
+	}{
+		{"tank forwards", func(d *ev3lib.DifferentialDrive) *ev3lib.Command { return d.Tank(0.5, 0.5) }, 1, 0},
+		{"tank backwards", func(d *ev3lib.DifferentialDrive) *ev3lib.Command { return d.Tank(-0.5, -0.5) }, -1, 0},
+		{"tank clockwise", func(d *ev3lib.DifferentialDrive) *ev3lib.Command { return d.Tank(0.3, -0.3) }, 0, 1},
+		{"arcade forwards", func(d *ev3lib.DifferentialDrive) *ev3lib.Command { return d.Arcade(0.5, 0) }, 1, 0},
+		{"arcade clockwise", func(d *ev3lib.DifferentialDrive) *ev3lib.Command { return d.Arcade(0, 0.3) }, 0, 1},
+		{"arcade counterclockwise", func(d *ev3lib.DifferentialDrive) *ev3lib.Command { return d.Arcade(0, -0.3) }, 0, -1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c, w, d := newSimDrive(t, true)
+			w.SetPose(0, 0, 0)
+
+			cmd := test.cmd(d)
+			cmd.Init()
+			for range 50 {
+				c.StepCommand(cmd, 10*time.Millisecond)
+				w.Update()
+			}
+			cmd.End(true)
+
+			x, _, heading := w.Pose()
+			if test.forward != 0 && x*test.forward < 50 {
+				t.Errorf("x = %v, want sign %v", x, test.forward)
+			}
+			if test.forward == 0 && math.Abs(x) > 1 {
+				t.Errorf("x = %v, want 0", x)
+			}
+
+			// The world measures headings counterclockwise, the drivetrain clockwise
+			if test.turn != 0 && (d.Heading()*test.turn < 10 || heading*test.turn > -10) {
+				t.Errorf("heading = %v, world heading = %v, want drivetrain sign %v", d.Heading(), heading, test.turn)
+			}
+			if test.turn == 0 && math.Abs(d.Heading()) > 1 {
+				t.Errorf("heading = %v, want 0", d.Heading())
+			}
+		})
+	}
+}
+
+// powerMotor records the power of each Set with the distance the motor had travelled.
+type powerMotor struct {
+	ev3lib.MotorInterface
+
+	powers, positions []float64
+}
+
+func (m *powerMotor) Set(power float64) {
+	m.powers = append(m.powers, power)
+	m.positions = append(m.positions, m.Position())
+	m.MotorInterface.Set(power)
+}
+
+func TestDriveStraight(t *testing.T) {
+	for _, distance := range []float64{300, -300} {
+		c, w, d := newSimDrive(t, true)
+
+		runSim(t, c, w, d.DriveStraight(distance, 0.6), 5*time.Second)
+
+		x, y, heading := w.Pose()
+		if math.Abs(x-distance) > 20 || math.Abs(y) > 5 || math.Abs(heading) > 2 {
+			t.Errorf("drive %v: pose = (%v, %v, %v), want (%v, 0, 0)", distance, x, y, heading, distance)
+		}
+	}
+}
+
+func TestDriveStraightRamp(t *testing.T) {
+	c, w, _ := newSimDrive(t, false)
+	left := &powerMotor{MotorInterface: w.LeftMotor()}
+	d := ev3lib.NewDifferentialDrive(left, w.RightMotor(), 56, 120, nil)
+	d.SetRamp(50, 100, 0.1)
+
+	const distance, speed = 500.0, 0.8
+	runSim(t, c, w, d.DriveStraight(distance, speed), 5*time.Second)
+
+	if left.powers[0] != 0.1 {
+		t.Errorf("first power = %v, want the min speed 0.1", left.powers[0])
+	}
+
+	peak := 0.0
+	for i, power := range left.powers {
+		travelled := left.positions[i] / 360 * math.Pi * 56
+		limit := min(speed, 0.1+(speed-0.1)*travelled/50, 0.1+(speed-0.1)*(distance-travelled)/100)
+
+		if power > max(limit, 0.1)+0.02 {
+			t.Errorf("power = %v after %vmm, want at most %v", power, travelled, limit)
+		}
+		peak = max(peak, power)
+	}
+
+	if math.Abs(peak-speed) > 0.02 {
+		t.Errorf("peak power = %v, want %v", peak, speed)
+	}
+}
+
+func TestTurnInPlace(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		angle float64
+		gyro  bool
+	}{
+		{"clockwise with gyro", 90, true},
+		{"counterclockwise with gyro", -90, true},
+		{"clockwise with wheels", 90, false},
+		{"counterclockwise with wheels", -90, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c, w, d := newSimDrive(t, test.gyro)
+
+			runSim(t, c, w, d.TurnInPlace(test.angle, 0.4), 5*time.Second)
+
+			x, y, heading := w.Pose()
+			if math.Abs(-heading-test.angle) > 5 {
+				t.Errorf("world heading = %v, want %v", heading, -test.angle)
+			}
+			if math.Hypot(x, y) > 5 {
+				t.Errorf("robot moved to (%v, %v) while turning in place", x, y)
+			}
+		})
+	}
+}
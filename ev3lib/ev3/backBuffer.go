@@ -0,0 +1,116 @@
+//go:build !ev3test
+
+package ev3
+
+import (
+	"image"
+	"sync"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/Alanlu217/ev3lib/ev3lib/gfx"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Back Buffer                                                                //
+////////////////////////////////////////////////////////////////////////////////
+
+var (
+	lcdLock sync.Mutex
+
+	// lcdOwner is the back buffer the LCD was last flushed from, or nil if it has been drawn on directly since.
+	lcdOwner *BackBuffer
+)
+
+// BackBuffer is an off-screen copy of the LCD. Screens are drawn on the buffer, then Flush copies
+// only the pixels that changed to the LCD, which avoids the flicker of clearing and redrawing the LCD.
+// It is a gfx.Canvas and an image.Image.
+type BackBuffer struct {
+	*gfx.Bitmap
+
+	// front is what the LCD showed after the last flush
+	front *gfx.Bitmap
+
+	minInterval time.Duration
+	lastFlush   time.Time
+}
+
+// NewBackBuffer creates a white buffer the size of the LCD, with no frame rate cap.
+func NewBackBuffer() *BackBuffer {
+	r := image.Rect(0, 0, LCDWidth, LCDHeight)
+	return &BackBuffer{Bitmap: gfx.NewBitmap(r), front: gfx.NewBitmap(r)}
+}
+
+// Graphics creates graphics for drawing on the buffer with the LCD font.
+func (b *BackBuffer) Graphics() *gfx.Graphics {
+	return gfx.New(b).SetFont(LCDFont)
+}
+
+// SetMaxFPS caps how often the buffer is flushed to the LCD. 0 removes the cap.
+func (b *BackBuffer) SetMaxFPS(fps float64) {
+	lcdLock.Lock()
+	defer lcdLock.Unlock()
+
+	if fps <= 0 {
+		b.minInterval = 0
+		return
+	}
+	b.minInterval = time.Duration(float64(time.Second) / fps)
+}
+
+// Flush copies the pixels that changed since the last flush to the LCD, returning the region that was copied.
+// The whole buffer is copied if anything else has drawn on the LCD since.
+// Under a frame rate cap a flush too soon after the last is skipped, returning an empty region, so the buffer should be flushed again later.
+func (b *BackBuffer) Flush() image.Rectangle {
+	if LCD == nil {
+		return image.Rectangle{}
+	}
+
+	lcdLock.Lock()
+	defer lcdLock.Unlock()
+
+	if b.minInterval > 0 && !b.lastFlush.IsZero() && ev3lib.Since(b.lastFlush) < b.minInterval {
+		return image.Rectangle{}
+	}
+
+	full := lcdOwner != b
+	lcdOwner = b
+
+	var dirty image.Rectangle
+	for y := 0; y < LCDHeight; y++ {
+		back := b.Pix[y*LCDWidth : (y+1)*LCDWidth]
+		front := b.front.Pix[y*LCDWidth : (y+1)*LCDWidth]
+
+		// Find the changed span of the row
+		x0, x1 := -1, -1
+		for x := range back {
+			if full || back[x] != front[x] {
+				if x0 < 0 {
+					x0 = x
+				}
+				x1 = x
+			}
+		}
+		if x0 < 0 {
+			continue
+		}
+
+		for x := x0; x <= x1; x++ {
+			LCD.set(x, y, back[x])
+		}
+		copy(front[x0:x1+1], back[x0:x1+1])
+
+		dirty = dirty.Union(image.Rect(x0, y, x1+1, y+1))
+	}
+
+	b.lastFlush = ev3lib.Now()
+	return dirty
+}
+
+// shown reports whether the LCD still shows the buffer, with nothing else flushed or drawn on it since its last flush.
+func (b *BackBuffer) shown() bool {
+	lcdLock.Lock()
+	defer lcdLock.Unlock()
+
+	return LCD == nil || lcdOwner == b
+}
@@ -0,0 +1,89 @@
+//go:build !ev3test
+
+package ev3
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/Alanlu217/ev3lib/ev3lib/testUtils"
+)
+
+// useMemoryLCD replaces the LCD with a white one in memory for the rest of the test.
+func useMemoryLCD(t *testing.T) *lcd {
+	prev, prevOwner := LCD, lcdOwner
+	t.Cleanup(func() { LCD, lcdOwner = prev, prevOwner })
+
+	LCD = &lcd{Data: make([]byte, LCDByteLength)}
+	LCD.Clear()
+	return LCD
+}
+
+func TestBackBufferFlush(t *testing.T) {
+	l := useMemoryLCD(t)
+
+	b := NewBackBuffer()
+	full := image.Rect(0, 0, LCDWidth, LCDHeight)
+
+	if got := b.Flush(); got != full {
+		t.Errorf("first flush copied %v, want %v", got, full)
+	}
+	if got := b.Flush(); !got.Empty() {
+		t.Errorf("flush without changes copied %v, want nothing", got)
+	}
+
+	b.SetPixel(3, 5, true)
+	b.SetPixel(10, 7, true)
+	if got, want := b.Flush(), image.Rect(3, 5, 11, 8); got != want {
+		t.Errorf("flush copied %v, want %v", got, want)
+	}
+	if !l.Pixel(3, 5) || !l.Pixel(10, 7) || l.Pixel(4, 5) {
+		t.Error("changed pixels not copied to the LCD")
+	}
+
+	b.SetPixel(3, 5, false)
+	if got, want := b.Flush(), image.Rect(3, 5, 4, 6); got != want {
+		t.Errorf("flush after clearing a pixel copied %v, want %v", got, want)
+	}
+	if l.Pixel(3, 5) {
+		t.Error("cleared pixel still black on the LCD")
+	}
+
+	// Another buffer or drawing on the LCD directly means the whole buffer has to be copied again
+	NewBackBuffer().Flush()
+	if got := b.Flush(); got != full {
+		t.Errorf("flush after another buffer copied %v, want %v", got, full)
+	}
+
+	l.SetPixel(0, 0, true)
+	if got := b.Flush(); got != full {
+		t.Errorf("flush after drawing on the LCD copied %v, want %v", got, full)
+	}
+	if l.Pixel(0, 0) {
+		t.Error("pixel drawn on the LCD not overwritten")
+	}
+}
+
+func TestBackBufferMaxFPS(t *testing.T) {
+	useMemoryLCD(t)
+
+	prev := ev3lib.GetClock()
+	t.Cleanup(func() { ev3lib.SetClock(prev) })
+	c := testUtils.UseManualClock()
+
+	b := NewBackBuffer()
+	b.SetMaxFPS(10)
+	b.Flush()
+
+	b.SetPixel(1, 1, true)
+	if got := b.Flush(); !got.Empty() {
+		t.Errorf("flush under the frame rate cap copied %v, want nothing", got)
+	}
+
+	c.Step(100 * time.Millisecond)
+	if got, want := b.Flush(), image.Rect(1, 1, 2, 2); got != want {
+		t.Errorf("flush after the frame interval copied %v, want %v", got, want)
+	}
+}
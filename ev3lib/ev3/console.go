@@ -0,0 +1,151 @@
+//go:build !ev3test
+
+package ev3
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Alanlu217/ev3lib/ev3lib/gfx"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Console                                                                    //
+////////////////////////////////////////////////////////////////////////////////
+
+const (
+	// ConsoleColumns is the number of characters that fit on a line of the LCD.
+	ConsoleColumns = LCDWidth / CharWidth
+
+	// ConsoleRows is the number of lines that fit on the LCD.
+	ConsoleRows = LCDHeight / CharHeight
+
+	consoleTabWidth = 4
+)
+
+// Console is a scrolling text terminal on the LCD, which can be used with fmt.Fprintf or log.SetOutput.
+// Long lines wrap, \n starts a new line and \r returns to the start of the line so it can be overwritten.
+type Console struct {
+	m sync.Mutex
+
+	lines  [][]rune
+	column int
+
+	// partial holds the bytes of a rune split across writes
+	partial []byte
+
+	buffer   *BackBuffer
+	graphics *gfx.Graphics
+}
+
+// NewConsole creates an empty console. Consoles share the LCD, so the last one written to is shown.
+func NewConsole() *Console {
+	c := &Console{buffer: NewBackBuffer()}
+	c.graphics = c.buffer.Graphics()
+	c.clear()
+	return c
+}
+
+// Write writes text to the console and redraws the LCD.
+func (c *Console) Write(p []byte) (int, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	b := append(c.partial, p...)
+	for len(b) > 0 {
+		if !utf8.FullRune(b) {
+			break
+		}
+		r, size := utf8.DecodeRune(b)
+		b = b[size:]
+
+		c.writeRune(r)
+	}
+	c.partial = append([]byte(nil), b...)
+
+	c.draw()
+	return len(p), nil
+}
+
+func (c *Console) writeRune(r rune) {
+	switch r {
+	case '\n':
+		c.newLine()
+	case '\r':
+		c.column = 0
+	case '\t':
+		c.put(' ')
+		for c.column%consoleTabWidth != 0 {
+			c.put(' ')
+		}
+	default:
+		c.put(r)
+	}
+}
+
+// put writes a character at the cursor, wrapping onto a new line if the current one is full.
+func (c *Console) put(r rune) {
+	if c.column >= ConsoleColumns {
+		c.newLine()
+	}
+
+	line := c.lines[len(c.lines)-1]
+	if c.column < len(line) {
+		line[c.column] = r
+	} else {
+		c.lines[len(c.lines)-1] = append(line, r)
+	}
+	c.column++
+}
+
+// newLine moves the cursor to a new line, scrolling the oldest off the top.
+func (c *Console) newLine() {
+	c.lines = append(c.lines, []rune{})
+	if len(c.lines) > ConsoleRows {
+		c.lines = c.lines[1:]
+	}
+	c.column = 0
+}
+
+func (c *Console) clear() {
+	c.lines = [][]rune{{}}
+	c.column = 0
+}
+
+// Clear clears the console and the LCD.
+func (c *Console) Clear() {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.clear()
+	c.draw()
+}
+
+// Lines returns the lines shown on the console, from the top.
+func (c *Console) Lines() []string {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	lines := make([]string, len(c.lines))
+	for i, line := range c.lines {
+		lines[i] = strings.TrimRight(string(line), " ")
+	}
+	return lines
+}
+
+// Redraw draws the console on the LCD again, e.g. after drawing over it.
+func (c *Console) Redraw() {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.draw()
+}
+
+func (c *Console) draw() {
+	c.graphics.Clear()
+	for i, line := range c.lines {
+		c.graphics.DrawText(0, i*CharHeight, string(line))
+	}
+	c.buffer.Flush()
+}
@@ -0,0 +1,65 @@
+//go:build !ev3test
+
+package ev3_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Alanlu217/ev3lib/ev3lib/ev3"
+)
+
+func TestConsoleWrite(t *testing.T) {
+	long := strings.Repeat("a", ev3.ConsoleColumns)
+
+	for _, test := range []struct {
+		name   string
+		writes []string
+		want   []string
+	}{
+		{"empty", nil, []string{""}},
+		{"line", []string{"hello\n"}, []string{"hello", ""}},
+		{"no newline", []string{"hello"}, []string{"hello"}},
+		{"split write", []string{"hel", "lo\nwor", "ld"}, []string{"hello", "world"}},
+		{"carriage return", []string{"12345\rab"}, []string{"ab345"}},
+		{"tab", []string{"a\tb"}, []string{"a   b"}},
+		{"full line", []string{long}, []string{long}},
+		{"wrap", []string{long + "bc"}, []string{long, "bc"}},
+		{"full line then newline", []string{long + "\n"}, []string{long, ""}},
+		{"split rune", []string{"\xc3", "\xa9"}, []string{"é"}},
+	} {
+		c := ev3.NewConsole()
+		for _, w := range test.writes {
+			c.Write([]byte(w))
+		}
+
+		if got := c.Lines(); !slices.Equal(got, test.want) {
+			t.Errorf("%v: lines = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestConsoleScroll(t *testing.T) {
+	c := ev3.NewConsole()
+	for i := range ev3.ConsoleRows + 3 {
+		fmt.Fprintf(c, "line %d\n", i)
+	}
+
+	lines := c.Lines()
+	if len(lines) != ev3.ConsoleRows {
+		t.Fatalf("%d lines shown, want %d", len(lines), ev3.ConsoleRows)
+	}
+	if lines[0] != "line 4" {
+		t.Errorf("top line = %q, want line 4", lines[0])
+	}
+	if last := lines[len(lines)-1]; last != "" {
+		t.Errorf("bottom line = %q, want empty", last)
+	}
+
+	c.Clear()
+	if got := c.Lines(); !slices.Equal(got, []string{""}) {
+		t.Errorf("lines after clear = %q, want one empty line", got)
+	}
+}
@@ -0,0 +1,198 @@
+//go:build !ev3test
+
+package ev3
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Read Error Policy                                                          //
+////////////////////////////////////////////////////////////////////////////////
+
+// ReadErrorPolicy decides what a device does when it cannot be read, such as when a cable comes loose.
+type ReadErrorPolicy int
+
+const (
+	// ReturnLastGood returns the last value that was read successfully.
+	ReturnLastGood ReadErrorPolicy = iota
+
+	// Retry reads again up to the configured number of retries, then returns the last good value.
+	Retry
+
+	// RaiseFault stops all motors and panics with a DeviceFault.
+	RaiseFault
+)
+
+var (
+	readErrorPolicy = ReturnLastGood
+	readRetries     = 3
+)
+
+// SetReadErrorPolicy sets the policy used by all devices when a read fails. Defaults to ReturnLastGood.
+func SetReadErrorPolicy(policy ReadErrorPolicy) {
+	readErrorPolicy = policy
+}
+
+// SetReadRetries sets the number of times a read is retried under the Retry policy. Defaults to 3.
+func SetReadRetries(retries int) {
+	readRetries = retries
+}
+
+// DeviceFault is the value panicked with under the RaiseFault policy.
+type DeviceFault struct {
+	Device string
+	Err    error
+}
+
+func (d DeviceFault) Error() string {
+	return fmt.Sprintf("ev3: fault reading %v: %v", d.Device, d.Err)
+}
+
+func (d DeviceFault) Unwrap() error {
+	return d.Err
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Device Errors                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+// valueKey identifies a last good value, such as value 0 of a sensor mode.
+type valueKey struct {
+	name string
+	n    int
+}
+
+// deviceErrors keeps track of the last good values and the first error of a device.
+// It is safe to use from multiple goroutines, such as a SensorSampler and a command.
+type deviceErrors struct {
+	name string
+
+	m    sync.Mutex
+	err  error
+	last map[valueKey]float64
+}
+
+func newDeviceErrors(name string) *deviceErrors {
+	return &deviceErrors{name: name, last: map[valueKey]float64{}}
+}
+
+// Err returns the first error since the last call to Err and clears it.
+func (d *deviceErrors) Err() error {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	err := d.err
+	d.err = nil
+	return err
+}
+
+// fail records err if it is the first error since the last call to Err.
+func (d *deviceErrors) fail(action string, err error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	if d.err == nil {
+		log.Printf("ev3: failed to %v %v: %v\n", action, d.name, err)
+		d.err = err
+	}
+}
+
+// read calls f according to the read error policy, storing the result under key as the last good value.
+// If every attempt fails the last good value is returned, with the error of the last attempt.
+func (d *deviceErrors) read(key valueKey, f func() (float64, error)) (float64, error) {
+	attempts := 1
+	if readErrorPolicy == Retry {
+		attempts += readRetries
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		var val float64
+		val, err = f()
+		if err == nil {
+			d.m.Lock()
+			d.last[key] = val
+			d.m.Unlock()
+			return val, nil
+		}
+	}
+
+	d.fail("read", err)
+
+	if readErrorPolicy == RaiseFault {
+		StopAllMotors()
+		panic(DeviceFault{Device: d.name, Err: err})
+	}
+
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	return d.last[key], err
+}
+
+// write calls f, recording its error like a failed read. Writes are not retried.
+func (d *deviceErrors) write(f func() error) {
+	if err := f(); err != nil {
+		d.fail("write", err)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Sensor Device                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+// valueAttributes are the names of the value attributes of a sensor, precomputed as they are read in every loop.
+var valueAttributes = [...]string{"value0", "value1", "value2", "value3", "value4", "value5", "value6", "value7"}
+
+// sensorDevice reads integer values from a sensor following the read error policy.
+type sensorDevice struct {
+	*deviceErrors
+
+	sensor *sysfsDevice
+
+	// Held while switching modes and reading, so values are read in the mode they were asked for
+	m    sync.Mutex
+	mode string
+}
+
+func newSensorDevice(sensor *sysfsDevice, port ev3lib.EV3Port) *sensorDevice {
+	return &sensorDevice{deviceErrors: newDeviceErrors(fmt.Sprintf("sensor on %v", port)), sensor: sensor}
+}
+
+// setMode switches the sensor to a mode if it is not already in it, as switching modes is slow.
+func (s *sensorDevice) setMode(mode string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.switchMode(mode)
+}
+
+func (s *sensorDevice) switchMode(mode string) {
+	if s.mode != mode {
+		s.write(func() error {
+			if err := s.sensor.setAttribute("mode", mode); err != nil {
+				return err
+			}
+			s.mode = mode
+			return nil
+		})
+	}
+}
+
+// value returns value n of the sensor and the error reading it, switching to the provided mode first.
+func (s *sensorDevice) value(mode string, n int) (int, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.switchMode(mode)
+	val, err := s.read(valueKey{mode, n}, func() (float64, error) {
+		val, err := s.sensor.intAttribute(valueAttributes[n])
+		return float64(val), err
+	})
+	return int(val), err
+}
@@ -0,0 +1,156 @@
+//go:build !ev3test
+
+package ev3_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/Alanlu217/ev3lib/ev3lib/ev3"
+)
+
+func TestConcurrentReads(t *testing.T) {
+	tree := newTree(t)
+
+	fake, err := tree.AddColorSensor(ev3lib.IN4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.SetValues(51, 102, 153)
+
+	s, err := ev3.NewColorSensor(ev3lib.IN4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := s.GetRGBErr(); err != nil {
+		t.Fatal(err)
+	}
+
+	fake.Disconnect()
+
+	// Another goroutine clearing the sticky error must not hide the error of a read
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				s.Err()
+			}
+		}
+	}()
+	defer close(stop)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r, g, b, err := s.GetRGBErr()
+				if err == nil {
+					t.Error("read of a disconnected sensor returned no error")
+					return
+				}
+				if r != 0.05 || g != 0.1 || b != 0.15 {
+					t.Errorf("read %v, %v, %v while disconnected, want the last good values", r, g, b)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	fake.SetValues(0, 0, 0)
+	if _, _, _, err := s.GetRGBErr(); err != nil {
+		t.Errorf("read after reconnecting returned %v", err)
+	}
+}
+
+func TestReadErrorsPerReading(t *testing.T) {
+	tree := newTree(t)
+
+	fake, err := tree.AddUltrasonicSensor(ev3lib.IN1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ev3.NewUltrasonicSensor(ev3lib.IN1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake.SetRawValue(0, "bad")
+	if _, err := s.DistanceErr(); err == nil {
+		t.Error("unparseable reading returned no error")
+	}
+
+	// The sticky error is left for Err, and only a failing read reports an error
+	fake.SetValues(20)
+	if d, err := s.DistanceErr(); d != 20 || err != nil {
+		t.Errorf("DistanceErr() = %v, %v, want 20, nil", d, err)
+	}
+	if err := s.Err(); err == nil {
+		t.Error("Err() did not report the earlier failed read")
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("Err() = %v after being cleared", err)
+	}
+}
+
+func TestSampledReadErrors(t *testing.T) {
+	tree := newTree(t)
+
+	fakeGyro, err := tree.AddGyroSensor(ev3lib.IN1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeGyro.SetValues(45, 0)
+	if _, err := tree.AddColorSensor(ev3lib.IN2); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := ev3.NewGyroSensor(ev3lib.IN1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ev3.NewColorSensor(ev3lib.IN2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sampler := ev3lib.NewSensorSampler()
+	gyro := ev3lib.NewGyroSensorBase(sampler.GyroSensor(g, time.Millisecond))
+	color := ev3lib.NewColorSensorBase(sampler.ColorSensor(c, time.Millisecond))
+
+	// Calibrations are saved under the port, so it must be passed through
+	if port := color.Port(); port != ev3lib.IN2 {
+		t.Errorf("sampled color sensor port = %q, want %q", port, ev3lib.IN2)
+	}
+
+	if angle, err := gyro.AngleErr(); angle != 45 || err != nil {
+		t.Fatalf("AngleErr() = %v, %v, want 45, nil", angle, err)
+	}
+
+	sampler.Start()
+	defer sampler.Stop()
+
+	// Errors while polling in the background are reported by the next read
+	fakeGyro.Disconnect()
+	deadline := time.Now().Add(time.Second)
+	for {
+		angle, err := gyro.AngleErr()
+		if err != nil {
+			if angle != 45 {
+				t.Errorf("angle = %v while disconnected, want the last good 45", angle)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("AngleErr() never reported the disconnected gyro")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -2,26 +2,57 @@ package ev3
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/Alanlu217/ev3lib/ev3lib/gfx"
 )
 
 const maxRows int = (LCDHeight / CharHeight) - 1
 
+// LowBatteryVoltage is the voltage below which the main menu shows the low battery status.
+var LowBatteryVoltage = 7.0
+
 ////////////////////////////////////////////////////////////////////////////////
 // EV3 Main Menu                                                              //
 ////////////////////////////////////////////////////////////////////////////////
 
 var _ ev3lib.MainMenuInterface = &EV3MainMenu{}
+var _ ev3lib.MenuStatusInterface = &EV3MainMenu{}
 
 type EV3MainMenu struct {
 	ev3 *ev3lib.EV3Brick
 
 	idx int
+
+	buffer   *BackBuffer
+	graphics *gfx.Graphics
+
+	status, shownStatus ev3lib.MenuStatus
+
+	// ran is set while a command runs, and held once it ends with something else on the LCD, such as console output
+	ran, held bool
 }
 
 func NewEV3MainMenu(ev3 *ev3lib.EV3Brick, m *ev3lib.Menu) *ev3lib.MainMenu {
-	return ev3lib.NewMainMenu(&EV3MainMenu{ev3, 0}, m)
+	return ev3lib.NewMainMenu(newEV3MainMenu(ev3), m)
+}
+
+func newEV3MainMenu(ev3 *ev3lib.EV3Brick) *EV3MainMenu {
+	buffer := NewBackBuffer()
+	return &EV3MainMenu{ev3: ev3, buffer: buffer, graphics: buffer.Graphics(), shownStatus: -1}
+}
+
+// pressed returns whether a button was pressed. While the menu is held the press only returns to the menu.
+func (e *EV3MainMenu) pressed(button ev3lib.EV3Button) bool {
+	if !e.ev3.IsButtonPressed(button) {
+		return false
+	}
+	if e.held {
+		e.held = false
+		return false
+	}
+	return true
 }
 
 func (e *EV3MainMenu) Exit() bool {
@@ -29,7 +60,7 @@ func (e *EV3MainMenu) Exit() bool {
 }
 
 func (e *EV3MainMenu) RunSelected() bool {
-	return e.ev3.IsButtonPressed(ev3lib.Middle)
+	return e.pressed(ev3lib.Middle)
 }
 
 func (e *EV3MainMenu) CancelRun() bool {
@@ -37,11 +68,11 @@ func (e *EV3MainMenu) CancelRun() bool {
 }
 
 func (e *EV3MainMenu) NextCommand() bool {
-	return e.ev3.IsButtonPressed(ev3lib.Down)
+	return e.pressed(ev3lib.Down)
 }
 
 func (e *EV3MainMenu) PreviousCommand() bool {
-	return e.ev3.IsButtonPressed(ev3lib.Up)
+	return e.pressed(ev3lib.Up)
 }
 
 func (e *EV3MainMenu) SetCommand() (bool, int) {
@@ -49,7 +80,7 @@ func (e *EV3MainMenu) SetCommand() (bool, int) {
 }
 
 func (e *EV3MainMenu) NextPage() bool {
-	if e.ev3.IsButtonPressed(ev3lib.Right) {
+	if e.pressed(ev3lib.Right) {
 		e.idx = 0
 		return true
 	}
@@ -57,7 +88,7 @@ func (e *EV3MainMenu) NextPage() bool {
 }
 
 func (e *EV3MainMenu) PreviousPage() bool {
-	if e.ev3.IsButtonPressed(ev3lib.Left) {
+	if e.pressed(ev3lib.Left) {
 		e.idx = 0
 		return true
 	}
@@ -68,15 +99,32 @@ func (e *EV3MainMenu) SetPage() (bool, int) {
 	return false, 0
 }
 
+// Display draws the menu on a back buffer and flushes it, so only the parts that changed are redrawn.
+// When a command ends with something else on the LCD, such as console output, the menu is not drawn until a button is pressed.
 func (e *EV3MainMenu) Display(menu *ev3lib.Menu, command int, page int, running bool) {
-	e.ev3.ClearScreen()
+	g := e.graphics
 
 	if running {
-		e.ev3.DrawText(0, 0, menu.Pages[page].Commands[command].Name)
+		e.ran = true
+
+		g.Clear()
+		g.DrawText(0, 0, menu.Pages[page].Commands[command].Name)
+		e.buffer.Flush()
+
+		return
+	}
 
+	if e.ran {
+		e.ran = false
+		e.held = !e.buffer.shown()
+	}
+	if e.held {
+		e.showStatus()
 		return
 	}
 
+	g.Clear()
+
 	start := command - 1
 	start = max(start, 0)
 
@@ -85,14 +133,47 @@ func (e *EV3MainMenu) Display(menu *ev3lib.Menu, command int, page int, running
 	idx := 0
 	for i := start; i < end; i++ {
 		if i == command {
-			e.ev3.DrawText(0, idx*CharHeight, fmt.Sprintf("> %v", menu.Pages[page].Commands[i].Name))
+			g.DrawText(0, idx*CharHeight, fmt.Sprintf("> %v", menu.Pages[page].Commands[i].Name))
 		} else {
-			e.ev3.DrawText(0, idx*CharHeight, fmt.Sprintf("  %v", menu.Pages[page].Commands[i].Name))
+			g.DrawText(0, idx*CharHeight, fmt.Sprintf("  %v", menu.Pages[page].Commands[i].Name))
 		}
 		idx++
 	}
 
-	e.ev3.DrawText(0, maxRows*CharHeight, fmt.Sprintf("%.2fV", e.ev3.Voltage()))
+	g.DrawText(0, maxRows*CharHeight, fmt.Sprintf("%.2fV", e.ev3.Voltage()))
+	e.buffer.Flush()
 
-	// fmt.Println(e.ev3.ButtonsPressed())
+	e.showStatus()
+}
+
+// SetStatus shows the menu status on the status lights:
+// solid green when idle, pulsing green while running, solid red on a fault and blinking amber on low battery.
+func (e *EV3MainMenu) SetStatus(status ev3lib.MenuStatus) {
+	e.status = status
+	e.showStatus()
+}
+
+func (e *EV3MainMenu) showStatus() {
+	status := e.status
+	if voltage := e.ev3.Voltage(); status == ev3lib.MenuIdle && voltage > 0 && voltage < LowBatteryVoltage {
+		status = ev3lib.MenuLowBattery
+	}
+
+	// Only change the lights when needed so patterns are not restarted
+	if status == e.shownStatus {
+		return
+	}
+	e.shownStatus = status
+
+	switch status {
+	case ev3lib.MenuIdle:
+		e.ev3.SetLight(ev3lib.GreenLight)
+	case ev3lib.MenuRunning:
+		e.ev3.PlayLightPattern(ev3lib.PulsePattern(ev3lib.GreenLight, time.Second))
+	case ev3lib.MenuFault:
+		// Solid so it stays on after the program ends
+		e.ev3.SetLight(ev3lib.RedLight)
+	case ev3lib.MenuLowBattery:
+		e.ev3.PlayLightPattern(ev3lib.BlinkPattern(ev3lib.AmberLight, 500*time.Millisecond, 500*time.Millisecond))
+	}
 }
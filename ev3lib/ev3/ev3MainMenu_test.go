@@ -0,0 +1,84 @@
+//go:build !ev3test
+
+package ev3
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/Alanlu217/ev3lib/ev3lib/testUtils"
+)
+
+// buttonBrick is a test brick whose buttons are pressed by the test. Each press is read once.
+type buttonBrick struct {
+	ev3lib.EV3BrickInterface
+	pressed map[ev3lib.EV3Button]bool
+}
+
+func (b *buttonBrick) IsButtonPressed(button ev3lib.EV3Button) bool {
+	pressed := b.pressed[button]
+	delete(b.pressed, button)
+	return pressed
+}
+
+func TestMainMenuKeepsConsole(t *testing.T) {
+	l := useMemoryLCD(t)
+
+	brick := &buttonBrick{EV3BrickInterface: testUtils.NewTestEV3Brick().EV3BrickInterface, pressed: map[ev3lib.EV3Button]bool{}}
+	menu := ev3lib.NewCommandMenu()
+	menu.AddPage("main").AddCommand("first", nil).AddCommand("second", nil).Add()
+
+	m := newEV3MainMenu(ev3lib.NewEV3BrickBase(brick))
+	c := NewConsole()
+
+	screen := func() []byte {
+		return bytes.Clone(l.Data)
+	}
+
+	m.Display(menu, 0, 0, false)
+	idle := screen()
+
+	// A command without output returns straight to the menu
+	m.Display(menu, 0, 0, true)
+	m.Display(menu, 0, 0, false)
+	if !bytes.Equal(screen(), idle) {
+		t.Error("menu not redrawn after a command without output")
+	}
+
+	// Output from a command stays on the LCD
+	m.Display(menu, 0, 0, true)
+	fmt.Fprintln(c, "result 42")
+	output := screen()
+
+	for range 3 {
+		m.Display(menu, 0, 0, false)
+	}
+	if !bytes.Equal(screen(), output) {
+		t.Error("console output overwritten by the menu")
+	}
+
+	// The first press only returns to the menu
+	brick.pressed[ev3lib.Down] = true
+	if m.NextCommand() {
+		t.Error("press returning to the menu also moved to the next command")
+	}
+	m.Display(menu, 0, 0, false)
+	if !bytes.Equal(screen(), idle) {
+		t.Error("menu not redrawn after a button press")
+	}
+
+	brick.pressed[ev3lib.Down] = true
+	if !m.NextCommand() {
+		t.Error("press in the menu did not move to the next command")
+	}
+
+	// Output from before a command was drawn over by the menu, so it is not kept
+	fmt.Fprintln(c, "old output")
+	m.Display(menu, 0, 0, true)
+	m.Display(menu, 0, 0, false)
+	if !bytes.Equal(screen(), idle) {
+		t.Error("menu not redrawn over output from before the command")
+	}
+}
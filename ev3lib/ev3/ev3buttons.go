@@ -3,6 +3,7 @@ package ev3
 import (
 	"log"
 	"sync"
+	"time"
 
 	"github.com/Alanlu217/ev3lib/ev3lib"
 	"github.com/ev3go/ev3dev"
@@ -93,13 +94,31 @@ func (b *ev3ButtonHandler) updateButton(isUp bool, button ev3lib.EV3Button) {
 	}
 }
 
+const (
+	buttonRetryMin = 100 * time.Millisecond
+	buttonRetryMax = 5 * time.Second
+)
+
 func (b *ev3ButtonHandler) run() {
 	// t := time.NewTicker(50 * time.Millisecond)
 
+	retry := time.Duration(0)
 	for {
 		val, err := b.poller.Poll()
 		if err != nil {
-			log.Fatal(err)
+			// No button device when running off the brick, buttons stay up while polling backs off
+			if retry == 0 {
+				log.Println(err)
+				retry = buttonRetryMin
+			} else {
+				retry = min(retry*2, buttonRetryMax)
+			}
+			time.Sleep(retry)
+			continue
+		}
+		if retry != 0 {
+			log.Println("ev3: button polling recovered")
+			retry = 0
 		}
 
 		b.updateButton(val&ev3dev.Back == 0, ev3lib.Back)
@@ -3,7 +3,8 @@
 package ev3
 
 import (
-	"log"
+	"fmt"
+	"io"
 
 	"github.com/Alanlu217/ev3lib/ev3lib"
 	"github.com/ev3go/ev3dev"
@@ -16,13 +17,19 @@ import (
 var _ ev3lib.EV3BrickInterface = &ev3{}
 
 type ev3 struct {
-	p ev3dev.PowerSupply
+	*deviceErrors
+
+	p *sysfsDevice
 
 	b *ev3ButtonHandler
+
+	sound   soundPlayer
+	leds    *ev3LEDs
+	console *Console
 }
 
 func NewEV3() *ev3lib.EV3Brick {
-	ev3 := &ev3{p: "", b: newEv3ButtonHandler()}
+	ev3 := &ev3{deviceErrors: newDeviceErrors("power supply"), p: newPowerSupply(), b: newEv3ButtonHandler(), leds: newEV3LEDs(), console: NewConsole()}
 
 	go ev3.b.run()
 
@@ -49,71 +56,77 @@ func (e *ev3) ButtonsPressed() []ev3lib.EV3Button {
 	return e.b.getDown()
 }
 
-func (e *ev3) SetLight(color ev3lib.EV3Color) {
-	panic("not implemented") // TODO: Implement
-}
-
-func (e *ev3) Beep(frequency float64, duration float64) {
-	panic("not implemented") // TODO: Implement
-}
-
-func (e *ev3) PlayNotes(notes []ev3lib.EV3Note, tempo float64) {
-	panic("not implemented") // TODO: Implement
-}
-
-func (e *ev3) SetVolume(volume float64) {
-	panic("not implemented") // TODO: Implement
-}
-
 func (e *ev3) ClearScreen() {
 	LCD.Clear()
 }
 
 func (e *ev3) DrawText(x int, y int, text string) {
-	for i, char := range text {
+	drawText(x, y, text)
+}
+
+func drawText(x int, y int, text string) {
+	for i, char := range []rune(text) {
 		values := FontMap[char]
 
 		for _, coord := range values {
 			new_x := x + coord.x + i*CharWidth
 			if new_x <= LCDWidth {
-				e.DrawPixel(new_x, y+coord.y, true)
+				drawPixel(new_x, y+coord.y, true)
 			}
 		}
 	}
 }
 
+// PrintScreen prints each string on its own line of the console, scrolling older lines up.
 func (e *ev3) PrintScreen(text ...string) {
-	panic("not implemented") // TODO: Implement
+	for _, line := range text {
+		fmt.Fprintln(e.console, line)
+	}
+}
+
+// Console returns the scrolling text console on the LCD.
+func (e *ev3) Console() io.Writer {
+	return e.console
 }
 
 func (e *ev3) DrawPixel(x int, y int, black bool) {
-	if black {
-		LCD.Data[ev3lib.LCDPixelToIndex(x, y)] = 0
-		LCD.Data[ev3lib.LCDPixelToIndex(x, y)+1] = 0
-		LCD.Data[ev3lib.LCDPixelToIndex(x, y)+2] = 0
-		LCD.Data[ev3lib.LCDPixelToIndex(x, y)+3] = 0
-	} else {
-		LCD.Data[ev3lib.LCDPixelToIndex(x, y)] = 255
-		LCD.Data[ev3lib.LCDPixelToIndex(x, y)+1] = 255
-		LCD.Data[ev3lib.LCDPixelToIndex(x, y)+2] = 255
-		LCD.Data[ev3lib.LCDPixelToIndex(x, y)+3] = 255
+	drawPixel(x, y, black)
+}
+
+func drawPixel(x int, y int, black bool) {
+	LCD.SetPixel(x, y, black)
+}
+
+// newPowerSupply returns the first power supply, falling back to the EV3 battery.
+func newPowerSupply() *sysfsDevice {
+	if supplies, err := findDevices(ev3dev.PowerSupplyPath); err == nil && len(supplies) > 0 {
+		return supplies[0]
 	}
+	return newSysfsDevice(ev3dev.PowerSupplyPath, "legoev3-battery")
 }
 
-func (e *ev3) Voltage() float64 {
-	volt, err := e.p.Voltage()
-	if err != nil {
-		log.Fatal(err)
+// scaledAttribute returns a function reading an integer attribute multiplied by scale.
+func scaledAttribute(d *sysfsDevice, name string, scale float64) func() (float64, error) {
+	return func() (float64, error) {
+		val, err := d.intAttribute(name)
+		return float64(val) * scale, err
 	}
+}
+
+func (e *ev3) Voltage() float64 {
+	voltage, _ := e.VoltageErr()
+	return voltage
+}
 
-	return volt
+func (e *ev3) VoltageErr() (float64, error) {
+	return e.read(valueKey{name: "voltage"}, scaledAttribute(e.p, "voltage_now", 1e-6))
 }
 
 func (e *ev3) Current() float64 {
-	curr, err := e.p.Current()
-	if err != nil {
-		log.Fatal(err)
-	}
+	current, _ := e.CurrentErr()
+	return current
+}
 
-	return curr
+func (e *ev3) CurrentErr() (float64, error) {
+	return e.read(valueKey{name: "current"}, scaledAttribute(e.p, "current_now", 1e-3))
 }
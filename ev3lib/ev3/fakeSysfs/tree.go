@@ -0,0 +1,387 @@
+//go:build !ev3test
+
+// Package fakeSysfs builds a fake ev3dev sysfs tree in a directory and points the ev3 drivers at it.
+// This allows the ev3 package to be run and tested with go test on a PC.
+//
+// The tree is made of plain files, so attributes do not react to writes.
+// Tests should set the values expected for a sensor mode before reading them,
+// and check what the drivers wrote, e.g. the motor command, afterwards.
+package fakeSysfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/Alanlu217/ev3lib/ev3lib/ev3"
+	"github.com/ev3go/ev3dev"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Tree                                                                       //
+////////////////////////////////////////////////////////////////////////////////
+
+// Tree is a fake sysfs tree that the ev3 drivers read from and write to.
+type Tree struct {
+	Root string
+
+	previous string
+	ids      map[string]int
+}
+
+// New creates a fake sysfs tree in the root directory, such as one from testing.T.TempDir,
+// and points the ev3 drivers at it. Call Close to point them back at the real sysfs.
+func New(root string) (*Tree, error) {
+	for _, path := range []string{ev3dev.TachoMotorPath, ev3dev.SensorPath, ev3dev.PowerSupplyPath, ev3dev.LEDPath} {
+		if err := os.MkdirAll(filepath.Join(root, path), 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	t := &Tree{Root: root, previous: ev3.SysfsRoot(), ids: map[string]int{}}
+	ev3.SetSysfsRoot(root)
+
+	return t, nil
+}
+
+// Close points the ev3 drivers back at the sysfs tree used before the fake tree was created.
+func (t *Tree) Close() {
+	ev3.SetSysfsRoot(t.previous)
+}
+
+// newID returns the next unused device number for a kind of device, such as motor.
+func (t *Tree) newID(kind string) int {
+	id := t.ids[kind]
+	t.ids[kind]++
+	return id
+}
+
+func (t *Tree) addDevice(class, name string, attributes map[string]string) (Device, error) {
+	d := Device{path: filepath.Join(t.Root, class, name)}
+
+	if err := os.MkdirAll(d.path, 0o755); err != nil {
+		return d, err
+	}
+
+	for attr, value := range attributes {
+		if err := d.SetAttribute(attr, value); err != nil {
+			return d, err
+		}
+	}
+
+	return d, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Device                                                                     //
+////////////////////////////////////////////////////////////////////////////////
+
+// Device is a directory of attributes in the fake tree.
+type Device struct {
+	path string
+}
+
+// Path returns the directory of the device.
+func (d Device) Path() string {
+	return d.path
+}
+
+// Attribute returns the value of an attribute without the trailing newline.
+func (d Device) Attribute(name string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(d.path, name))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
+
+// SetAttribute sets the value of an attribute, creating it if needed.
+func (d Device) SetAttribute(name, value string) error {
+	path := filepath.Join(d.path, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(value+"\n"), 0o644)
+}
+
+func (d Device) intAttribute(name string) int {
+	val, err := d.Attribute(name)
+	if err != nil {
+		return 0
+	}
+	i, _ := strconv.Atoi(val)
+	return i
+}
+
+func (d Device) stringAttribute(name string) string {
+	val, _ := d.Attribute(name)
+	return val
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Tacho Motor                                                                //
+////////////////////////////////////////////////////////////////////////////////
+
+// Motor is a fake tacho motor.
+type Motor struct {
+	Device
+}
+
+// AddTachoMotor adds a tacho motor with the provided driver, such as lego-ev3-l-motor, to a port.
+func (t *Tree) AddTachoMotor(port ev3lib.EV3Port, driver string, maxSpeed int) (*Motor, error) {
+	d, err := t.addDevice(ev3dev.TachoMotorPath, fmt.Sprintf("motor%d", t.newID("motor")), map[string]string{
+		"address":       string(port),
+		"driver_name":   driver,
+		"count_per_rot": "360",
+		"max_speed":     strconv.Itoa(maxSpeed),
+		"commands":      "run-forever run-to-abs-pos run-to-rel-pos run-timed run-direct stop reset",
+		"stop_actions":  "coast brake hold",
+		"stop_action":   "coast",
+		"command":       "",
+		"duty_cycle":    "0",
+		"duty_cycle_sp": "0",
+		"polarity":      "normal",
+		"position":      "0",
+		"position_sp":   "0",
+		"speed":         "0",
+		"speed_sp":      "0",
+		"state":         "",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Motor{d}, nil
+}
+
+// AddLargeMotor adds an EV3 large motor to a port.
+func (t *Tree) AddLargeMotor(port ev3lib.EV3Port) (*Motor, error) {
+	return t.AddTachoMotor(port, "lego-ev3-l-motor", 1050)
+}
+
+// AddMediumMotor adds an EV3 medium motor to a port.
+func (t *Tree) AddMediumMotor(port ev3lib.EV3Port) (*Motor, error) {
+	return t.AddTachoMotor(port, "lego-ev3-m-motor", 1560)
+}
+
+// Command returns the last command written to the motor, such as run-direct or stop.
+func (m *Motor) Command() string {
+	return m.stringAttribute("command")
+}
+
+// DutyCycleSetpoint returns the duty cycle setpoint from -100 to 100.
+func (m *Motor) DutyCycleSetpoint() int {
+	return m.intAttribute("duty_cycle_sp")
+}
+
+// Polarity returns either normal or inversed.
+func (m *Motor) Polarity() string {
+	return m.stringAttribute("polarity")
+}
+
+// StopAction returns the stop action written to the motor.
+func (m *Motor) StopAction() string {
+	return m.stringAttribute("stop_action")
+}
+
+// Position returns the tacho count of the motor.
+func (m *Motor) Position() int {
+	return m.intAttribute("position")
+}
+
+// SetPosition sets the tacho count reported by the motor.
+func (m *Motor) SetPosition(pos int) error {
+	return m.SetAttribute("position", strconv.Itoa(pos))
+}
+
+// SetSpeed sets the speed reported by the motor in tacho counts per second.
+func (m *Motor) SetSpeed(speed int) error {
+	return m.SetAttribute("speed", strconv.Itoa(speed))
+}
+
+// SetState sets the state flags reported by the motor, such as running or stalled.
+func (m *Motor) SetState(states ...string) error {
+	return m.SetAttribute("state", strings.Join(states, " "))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Sensor                                                                     //
+////////////////////////////////////////////////////////////////////////////////
+
+// Sensor is a fake lego sensor.
+type Sensor struct {
+	Device
+}
+
+// AddSensor adds a sensor with the provided driver, such as lego-ev3-gyro, to a port.
+// The first mode is set as the current mode.
+func (t *Tree) AddSensor(port ev3lib.EV3Port, driver string, modes ...string) (*Sensor, error) {
+	attributes := map[string]string{
+		"address":         string(port),
+		"driver_name":     driver,
+		"fw_version":      "",
+		"commands":        "",
+		"modes":           strings.Join(modes, " "),
+		"mode":            modes[0],
+		"decimals":        "0",
+		"num_values":      "8",
+		"units":           "",
+		"bin_data_format": "s8",
+	}
+	for i := 0; i < 8; i++ {
+		attributes[fmt.Sprintf("value%d", i)] = "0"
+	}
+
+	d, err := t.addDevice(ev3dev.SensorPath, fmt.Sprintf("sensor%d", t.newID("sensor")), attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sensor{d}, nil
+}
+
+// AddColorSensor adds an EV3 color sensor to a port.
+func (t *Tree) AddColorSensor(port ev3lib.EV3Port) (*Sensor, error) {
+	return t.AddSensor(port, "lego-ev3-color", "COL-REFLECT", "COL-AMBIENT", "COL-COLOR", "REF-RAW", "RGB-RAW", "COL-CAL")
+}
+
+// AddGyroSensor adds an EV3 gyro sensor to a port.
+func (t *Tree) AddGyroSensor(port ev3lib.EV3Port) (*Sensor, error) {
+	return t.AddSensor(port, "lego-ev3-gyro", "GYRO-ANG", "GYRO-RATE", "GYRO-FAS", "GYRO-G&A", "GYRO-CAL", "TILT-RATE", "TILT-ANG")
+}
+
+// AddInfraredSensor adds an EV3 infrared sensor to a port.
+func (t *Tree) AddInfraredSensor(port ev3lib.EV3Port) (*Sensor, error) {
+	return t.AddSensor(port, "lego-ev3-ir", "IR-PROX", "IR-SEEK", "IR-REMOTE", "IR-REM-A", "IR-S-ALT", "IR-CAL")
+}
+
+// AddTouchSensor adds an EV3 touch sensor to a port.
+func (t *Tree) AddTouchSensor(port ev3lib.EV3Port) (*Sensor, error) {
+	return t.AddSensor(port, "lego-ev3-touch", "TOUCH")
+}
+
+// AddUltrasonicSensor adds an EV3 ultrasonic sensor to a port.
+func (t *Tree) AddUltrasonicSensor(port ev3lib.EV3Port) (*Sensor, error) {
+	return t.AddSensor(port, "lego-ev3-us", "US-DIST-CM", "US-DIST-IN", "US-LISTEN", "US-SI-CM", "US-SI-IN", "US-DC-CM", "US-DC-IN")
+}
+
+// Mode returns the mode last written to the sensor.
+func (s *Sensor) Mode() string {
+	return s.stringAttribute("mode")
+}
+
+// SetValues sets the raw values reported by the sensor, starting from value0.
+func (s *Sensor) SetValues(values ...int) error {
+	for i, v := range values {
+		if err := s.SetAttribute(fmt.Sprintf("value%d", i), strconv.Itoa(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetRawValue sets a single value to any string, which can be used to simulate unparseable readings.
+func (s *Sensor) SetRawValue(n int, value string) error {
+	return s.SetAttribute(fmt.Sprintf("value%d", n), value)
+}
+
+// Disconnect empties the sensor's values, causing reads to fail as if the cable were unplugged.
+// The drivers keep attribute files open, so the files are emptied rather than removed.
+func (s *Sensor) Disconnect() error {
+	for i := 0; i < 8; i++ {
+		if err := s.SetRawValue(i, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Power Supply                                                               //
+////////////////////////////////////////////////////////////////////////////////
+
+// PowerSupply is a fake EV3 battery.
+type PowerSupply struct {
+	Device
+}
+
+// AddPowerSupply adds the EV3 battery with a voltage in volts and current in milliamps.
+func (t *Tree) AddPowerSupply(voltage, current float64) (*PowerSupply, error) {
+	d, err := t.addDevice(ev3dev.PowerSupplyPath, "legoev3-battery", map[string]string{
+		"technology": "Unknown",
+		"type":       "Battery",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PowerSupply{d}
+	if err := p.SetVoltage(voltage); err != nil {
+		return nil, err
+	}
+	if err := p.SetCurrent(current); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetVoltage sets the battery voltage in volts.
+func (p *PowerSupply) SetVoltage(voltage float64) error {
+	return p.SetAttribute("voltage_now", strconv.Itoa(int(voltage*1e6)))
+}
+
+// SetCurrent sets the current draw in milliamps.
+func (p *PowerSupply) SetCurrent(current float64) error {
+	return p.SetAttribute("current_now", strconv.Itoa(int(current*1e3)))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Status Lights                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+// LED is a fake status light.
+type LED struct {
+	Device
+}
+
+// LEDs are the fake status lights, with red and green for each side.
+type LEDs struct {
+	LeftRed, LeftGreen, RightRed, RightGreen *LED
+}
+
+// AddLEDs adds the four status lights of the brick, turned off.
+func (t *Tree) AddLEDs() (*LEDs, error) {
+	add := func(name string) (*LED, error) {
+		d, err := t.addDevice(ev3dev.LEDPath, name, map[string]string{
+			"max_brightness": "255",
+			"brightness":     "0",
+			"trigger":        "[none] timer heartbeat default-on",
+		})
+		return &LED{d}, err
+	}
+
+	var l LEDs
+	var err error
+	for _, led := range []struct {
+		name string
+		dest **LED
+	}{
+		{"led0:red:brick-status", &l.LeftRed},
+		{"led0:green:brick-status", &l.LeftGreen},
+		{"led1:red:brick-status", &l.RightRed},
+		{"led1:green:brick-status", &l.RightGreen},
+	} {
+		if *led.dest, err = add(led.name); err != nil {
+			return nil, err
+		}
+	}
+	return &l, nil
+}
+
+// Brightness returns the brightness written to the light, from 0 to 255.
+func (l *LED) Brightness() int {
+	return l.intAttribute("brightness")
+}
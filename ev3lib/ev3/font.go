@@ -2,8 +2,11 @@ package ev3
 
 import (
 	_ "embed"
+	"image"
 	"math"
 	"strings"
+
+	"github.com/Alanlu217/ev3lib/ev3lib/gfx"
 )
 
 type RuneCoord struct {
@@ -56,3 +59,26 @@ func FontListToCoord(index int) (x int, y int) {
 
 	return
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// LCD Font                                                                   //
+////////////////////////////////////////////////////////////////////////////////
+
+// LCDFont is FontMap as a gfx.Font.
+var LCDFont gfx.Font = lcdFont{}
+
+type lcdFont struct{}
+
+func (lcdFont) Size() (int, int) {
+	return CharWidth, CharHeight
+}
+
+func (lcdFont) Glyph(r rune) []image.Point {
+	coords := FontMap[r]
+
+	points := make([]image.Point, len(coords))
+	for i, c := range coords {
+		points[i] = image.Pt(c.x, c.y)
+	}
+	return points
+}
@@ -3,9 +3,12 @@
 package ev3
 
 import (
+	"image"
 	"reflect"
 	"unsafe"
 
+	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/Alanlu217/ev3lib/ev3lib/gfx"
 	ev3go "github.com/ev3go/ev3"
 )
 
@@ -24,6 +27,8 @@ func init() {
 
 var LCD = newLcd()
 
+var _ gfx.Canvas = &lcd{}
+
 type lcd struct {
 	Data []byte
 }
@@ -49,5 +54,52 @@ func (l *lcd) Set(i int, b byte) {
 }
 
 func (l *lcd) Clear() {
+	lcdLock.Lock()
+	defer lcdLock.Unlock()
+
+	lcdOwner = nil
 	copy(l.Data, clearScreen[:])
 }
+
+func (l *lcd) Bounds() image.Rectangle {
+	return image.Rect(0, 0, LCDWidth, LCDHeight)
+}
+
+// Pixel returns whether a pixel is black, or false outside the screen.
+func (l *lcd) Pixel(x, y int) bool {
+	if !image.Pt(x, y).In(l.Bounds()) {
+		return false
+	}
+	return l.Data[ev3lib.LCDPixelToIndex(x, y)] == 0
+}
+
+// SetPixel sets a pixel to black or white, ignoring pixels outside the screen.
+func (l *lcd) SetPixel(x, y int, black bool) {
+	if !image.Pt(x, y).In(l.Bounds()) {
+		return
+	}
+
+	lcdLock.Lock()
+	defer lcdLock.Unlock()
+
+	lcdOwner = nil
+	l.set(x, y, black)
+}
+
+func (l *lcd) set(x, y int, black bool) {
+	var b byte = 255
+	if black {
+		b = 0
+	}
+
+	i := ev3lib.LCDPixelToIndex(x, y)
+	l.Data[i] = b
+	l.Data[i+1] = b
+	l.Data[i+2] = b
+	l.Data[i+3] = b
+}
+
+// NewLCDGraphics creates graphics for drawing on the LCD with the LCD font.
+func NewLCDGraphics() *gfx.Graphics {
+	return gfx.New(LCD).SetFont(LCDFont)
+}
@@ -0,0 +1,86 @@
+//go:build !ev3test
+
+package ev3
+
+import (
+	"math"
+	"strconv"
+	"sync"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/ev3go/ev3dev"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Status Lights                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+// ev3LEDs drives the red and green status lights on each side of the brick.
+type ev3LEDs struct {
+	*deviceErrors
+
+	m sync.Mutex
+
+	// Red and green handles indexed by ev3lib.EV3LED
+	leds          [2][2]*sysfsDevice
+	maxBrightness [2][2]int
+
+	pattern *ev3lib.LightPatternPlayer
+}
+
+func newEV3LEDs() *ev3LEDs {
+	l := &ev3LEDs{
+		deviceErrors: newDeviceErrors("status lights"),
+		leds: [2][2]*sysfsDevice{
+			{newSysfsDevice(ev3dev.LEDPath, "led0:red:brick-status"), newSysfsDevice(ev3dev.LEDPath, "led0:green:brick-status")},
+			{newSysfsDevice(ev3dev.LEDPath, "led1:red:brick-status"), newSysfsDevice(ev3dev.LEDPath, "led1:green:brick-status")},
+		},
+	}
+	l.pattern = ev3lib.NewLightPatternPlayer(func(color ev3lib.EV3Color) {
+		l.set(ev3lib.LeftLED, color)
+		l.set(ev3lib.RightLED, color)
+	})
+	return l
+}
+
+// set shows a color on one side, mapped to the brightness of its red and green lights.
+func (l *ev3LEDs) set(led ev3lib.EV3LED, color ev3lib.EV3Color) {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	red, green := color.LEDBrightness()
+	for i, brightness := range []float64{red, green} {
+		handle := l.leds[led][i]
+
+		l.write(func() error {
+			if l.maxBrightness[led][i] == 0 {
+				max, err := handle.intAttribute("max_brightness")
+				if err != nil {
+					return err
+				}
+				l.maxBrightness[led][i] = max
+			}
+
+			return handle.setAttribute("brightness", strconv.Itoa(int(math.Round(brightness*float64(l.maxBrightness[led][i])))))
+		})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// EV3Brick Lights                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+func (e *ev3) SetLight(color ev3lib.EV3Color) {
+	e.leds.pattern.Stop()
+	e.leds.set(ev3lib.LeftLED, color)
+	e.leds.set(ev3lib.RightLED, color)
+}
+
+func (e *ev3) SetLED(led ev3lib.EV3LED, color ev3lib.EV3Color) {
+	e.leds.pattern.Stop()
+	e.leds.set(led, color)
+}
+
+func (e *ev3) PlayLightPattern(pattern ev3lib.LightPattern) {
+	e.leds.pattern.Play(pattern)
+}
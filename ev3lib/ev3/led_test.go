@@ -0,0 +1,54 @@
+//go:build !ev3test
+
+package ev3_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/Alanlu217/ev3lib/ev3lib/ev3"
+)
+
+func TestLEDs(t *testing.T) {
+	tree := newTree(t)
+
+	leds, err := tree.AddLEDs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Brightness is scaled to each light's own maximum
+	if err := leds.RightGreen.SetAttribute("max_brightness", "100"); err != nil {
+		t.Fatal(err)
+	}
+
+	brick := ev3.NewEV3()
+
+	check := func(name string, want [4]int) {
+		t.Helper()
+
+		got := [4]int{leds.LeftRed.Brightness(), leds.LeftGreen.Brightness(), leds.RightRed.Brightness(), leds.RightGreen.Brightness()}
+		if got != want {
+			t.Errorf("%v: brightness = %v, want %v", name, got, want)
+		}
+	}
+
+	brick.SetLight(ev3lib.RedLight)
+	check("red", [4]int{255, 0, 255, 0})
+
+	brick.SetLight(ev3lib.AmberLight.WithBrightness(0.5))
+	check("half amber", [4]int{128, 128, 128, 50})
+
+	brick.SetLED(ev3lib.RightLED, ev3lib.GreenLight)
+	check("right green", [4]int{128, 128, 0, 100})
+
+	brick.SetLight(ev3lib.OffLight)
+	check("off", [4]int{0, 0, 0, 0})
+
+	// Setting a light stops a pattern, leaving the set color
+	brick.PlayLightPattern(ev3lib.BlinkPattern(ev3lib.RedLight, time.Millisecond, time.Millisecond))
+	time.Sleep(10 * time.Millisecond)
+	brick.SetLight(ev3lib.GreenLight)
+	time.Sleep(10 * time.Millisecond)
+	check("green after pattern", [4]int{0, 255, 0, 100})
+}
@@ -3,6 +3,9 @@
 package ev3
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/Alanlu217/ev3lib/ev3lib"
 	"github.com/ev3go/ev3dev"
 )
@@ -16,69 +19,75 @@ const (
 	largeMotorDriverName  string = "lego-ev3-l-motor"
 )
 
-func StopAllMotors() {
-	m, err := ev3dev.TachoMotorFor("", mediumMotorDriverName)
-	for err == nil {
-		m.Command("stop")
-		temp, e := m.Next()
-
-		m = temp
-		err = e
-	}
-
-	m, err = ev3dev.TachoMotorFor("", largeMotorDriverName)
-	for err == nil {
-		m.Command("stop")
-		temp, e := m.Next()
+// motorStates maps the state flags of a tacho motor to ev3lib states.
+var motorStates = map[string]ev3lib.MotorState{
+	"running":    ev3lib.Running,
+	"ramping":    ev3lib.Ramping,
+	"holding":    ev3lib.Holding,
+	"overloaded": ev3lib.Overloaded,
+	"stalled":    ev3lib.Stalled,
+}
 
-		m = temp
-		err = e
+func StopAllMotors() {
+	motors, _ := findDevices(ev3dev.TachoMotorPath)
+	for _, m := range motors {
+		m.setAttribute("command", "stop")
 	}
 }
 
 type ev3Motor struct {
-	motor *ev3dev.TachoMotor
+	motor *sysfsDevice
 
-	scale float64
+	countPerRot int
+	scale       float64
 }
 
-func NewMediumMotor(port ev3lib.EV3Port) (*ev3lib.Motor, error) {
-	m, err := ev3dev.TachoMotorFor(string(port), mediumMotorDriverName)
+func newMotor(port ev3lib.EV3Port, driver string) (*ev3lib.Motor, error) {
+	m, err := findDevice(ev3dev.TachoMotorPath, port, driver)
 	if err != nil {
 		return nil, err
 	}
 
-	return ev3lib.NewMotorBase(&ev3Motor{motor: m, scale: 1}), nil
-}
-
-func NewLargeMotor(port ev3lib.EV3Port) (*ev3lib.Motor, error) {
-	m, err := ev3dev.TachoMotorFor(string(port), largeMotorDriverName)
+	countPerRot, err := m.intAttribute("count_per_rot")
 	if err != nil {
 		return nil, err
 	}
 
-	return ev3lib.NewMotorBase(&ev3Motor{motor: m, scale: 1}), nil
+	return ev3lib.NewMotorBase(&ev3Motor{motor: m, countPerRot: countPerRot, scale: 1}), nil
+}
+
+func NewMediumMotor(port ev3lib.EV3Port) (*ev3lib.Motor, error) {
+	return newMotor(port, mediumMotorDriverName)
+}
+
+func NewLargeMotor(port ev3lib.EV3Port) (*ev3lib.Motor, error) {
+	return newMotor(port, largeMotorDriverName)
 }
 
 func (m *ev3Motor) CountPerRot() int {
-	return m.motor.CountPerRot()
+	return m.countPerRot
 }
 
 func (m *ev3Motor) State() ev3lib.MotorState {
-	s, _ := m.motor.State()
-	return ev3lib.MotorState(s)
+	s, _ := m.motor.attribute("state")
+
+	var state ev3lib.MotorState
+	for _, flag := range strings.Fields(s) {
+		state |= motorStates[flag]
+	}
+	return state
 }
 
 func (m *ev3Motor) Inverted() bool {
-	p, _ := m.motor.Polarity()
-	return p == ev3dev.Inversed
+	p, _ := m.motor.attribute("polarity")
+	return p == string(ev3dev.Inversed)
 }
 
 func (m *ev3Motor) SetInverted(inverted bool) {
 	if inverted {
-		m.motor.SetPolarity(ev3dev.Inversed)
+		m.motor.setAttribute("polarity", string(ev3dev.Inversed))
 	} else {
-		m.motor.SetPolarity(ev3dev.Normal)
+		m.motor.setAttribute("polarity", string(ev3dev.Normal))
 	}
 }
 
@@ -91,32 +100,34 @@ func (m *ev3Motor) SetScale(scale float64) {
 }
 
 func (m *ev3Motor) Position() float64 {
-	p, _ := m.motor.Position()
+	p, _ := m.motor.intAttribute("position")
 	return float64(p) * m.scale
 }
 
 func (m *ev3Motor) ResetPosition(pos float64) {
-	m.motor.SetPosition(int(pos / m.scale))
+	m.motor.setAttribute("position", strconv.Itoa(int(pos/m.scale)))
 }
 
 func (m *ev3Motor) Speed() float64 {
-	s, _ := m.motor.Speed()
+	s, _ := m.motor.intAttribute("speed")
 	return float64(s) * m.scale
 }
 
 func (m *ev3Motor) Set(power float64) {
-	m.motor.SetDutyCycleSetpoint(int(power * 100)).Command("run-direct")
+	if m.motor.setAttribute("duty_cycle_sp", strconv.Itoa(int(power*100))) == nil {
+		m.motor.setAttribute("command", "run-direct")
+	}
 }
 
 func (m *ev3Motor) Stop() {
-	m.motor.Command("stop")
+	m.motor.setAttribute("command", "stop")
 }
 
 func (m *ev3Motor) StopAction() ev3lib.MotorStopAction {
-	s, _ := m.motor.StopAction()
+	s, _ := m.motor.attribute("stop_action")
 	return ev3lib.MotorStopAction(s)
 }
 
 func (m *ev3Motor) SetStopAction(s ev3lib.MotorStopAction) {
-	m.motor.SetStopAction(string(s))
+	m.motor.setAttribute("stop_action", string(s))
 }
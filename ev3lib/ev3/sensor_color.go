@@ -3,9 +3,6 @@
 package ev3
 
 import (
-	"log"
-	"strconv"
-
 	"github.com/Alanlu217/ev3lib/ev3lib"
 
 	"github.com/ev3go/ev3dev"
@@ -23,90 +20,96 @@ const (
 	colorSensorModeReflect colorSensorMode = "COL-REFLECT"
 	colorSensorModeAmbient colorSensorMode = "COL-AMBIENT"
 	colorSensorModeRGB     colorSensorMode = "RGB-RAW"
+	colorSensorModeColor   colorSensorMode = "COL-COLOR"
 )
 
 var _ ev3lib.ColorSensorInterface = &ev3ColorSensor{}
+var _ ev3lib.ColorDetectorInterface = &ev3ColorSensor{}
 
 // Provides access to the EV3 color sensor
 type ev3ColorSensor struct {
-	sensor *ev3dev.Sensor
+	*sensorDevice
 
-	minReflect, maxReflect float64
-	currentMode            colorSensorMode
+	port ev3lib.EV3Port
 }
 
 // NewColorSensor creates a new color sensor with the provided port.
-// Defaults calibration values of minReflect to 0, and maxReflect to 1.
 func NewColorSensor(port ev3lib.EV3Port) (*ev3lib.ColorSensor, error) {
-	sensor, err := ev3dev.SensorFor(string(port), colorSensorDriverName)
+	sensor, err := findDevice(ev3dev.SensorPath, port, colorSensorDriverName)
 	if err != nil {
 		return nil, err
 	}
 
-	sensor.SetMode(string(colorSensorModeReflect))
-	return ev3lib.NewColorSensorBase(&ev3ColorSensor{sensor: sensor, minReflect: 0, maxReflect: 1, currentMode: colorSensorModeReflect}), nil
+	s := &ev3ColorSensor{sensorDevice: newSensorDevice(sensor, port), port: port}
+	s.setMode(string(colorSensorModeReflect))
+
+	return ev3lib.NewColorSensorBase(s), nil
+}
+
+// Port returns the port the sensor is connected to, used to save its calibration.
+func (s *ev3ColorSensor) Port() ev3lib.EV3Port {
+	return s.port
 }
 
 // Ambient returns the ambient light intensity from 0 to 1.
 func (s *ev3ColorSensor) Ambient() float64 {
-	if s.currentMode != colorSensorModeAmbient {
-		s.sensor.SetMode(string(colorSensorModeAmbient))
-	}
-
-	val, err := s.sensor.Value(0)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	valInt, err := strconv.Atoi(val)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+	ambient, _ := s.AmbientErr()
+	return ambient
+}
 
-	ambient := float64(valInt) / 100
+func (s *ev3ColorSensor) AmbientErr() (float64, error) {
+	ambient, err := s.value(string(colorSensorModeAmbient), 0)
 
-	return ambient
+	return float64(ambient) / 100, err
 }
 
 // Reflection returns the reflected light intensity from 0 to 1.
 func (s *ev3ColorSensor) Reflection() float64 {
-	if s.currentMode != colorSensorModeReflect {
-		s.sensor.SetMode(string(colorSensorModeReflect))
-	}
-
-	val, err := s.sensor.Value(0)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	valInt, err := strconv.Atoi(val)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+	reflected, _ := s.ReflectionErr()
+	return reflected
+}
 
-	reflected := float64(valInt) / 100
+func (s *ev3ColorSensor) ReflectionErr() (float64, error) {
+	reflected, err := s.value(string(colorSensorModeReflect), 0)
 
-	return reflected
+	return float64(reflected) / 100, err
 }
 
 // GetRGB returns the measured color in RGB with each value from 0 to 1.
 func (s *ev3ColorSensor) GetRGB() (float64, float64, float64) {
-	if s.currentMode != colorSensorModeRGB {
-		s.sensor.SetMode(string(colorSensorModeRGB))
-	}
+	r, g, b, _ := s.GetRGBErr()
+	return r, g, b
+}
 
+// GetRGBErr returns the measured color and the first error reading its channels.
+func (s *ev3ColorSensor) GetRGBErr() (float64, float64, float64, error) {
 	rgb := [3]float64{0, 0, 0}
 
+	var err error
 	for i := 0; i < 3; i++ {
-		val, err := s.sensor.Value(i)
-		if err != nil {
-			log.Fatal(err.Error())
-		}
-		valInt, err := strconv.Atoi(val)
-		if err != nil {
-			log.Fatal(err.Error())
+		val, valErr := s.value(string(colorSensorModeRGB), i)
+		if err == nil {
+			err = valErr
 		}
+		rgb[i] = float64(val) / 1020
+	}
+
+	return rgb[0], rgb[1], rgb[2], err
+}
+
+// Color returns the color detected by the sensor, or NoColor if it is unsure.
+func (s *ev3ColorSensor) Color() ev3lib.LegoColor {
+	color, _ := s.ColorErr()
+	return color
+}
+
+func (s *ev3ColorSensor) ColorErr() (ev3lib.LegoColor, error) {
+	val, err := s.value(string(colorSensorModeColor), 0)
 
-		rgb[i] = float64(valInt) / 1020
+	color := ev3lib.LegoColor(val)
+	if color < ev3lib.NoColor || color > ev3lib.BrownColor {
+		return ev3lib.NoColor, err
 	}
 
-	return rgb[0], rgb[1], rgb[2]
+	return color, err
 }
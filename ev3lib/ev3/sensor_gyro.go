@@ -3,8 +3,7 @@
 package ev3
 
 import (
-	"log"
-	"strconv"
+	"sync"
 	"time"
 
 	"github.com/Alanlu217/ev3lib/ev3lib"
@@ -29,108 +28,149 @@ const (
 
 var _ ev3lib.GyroSensorInterface = &ev3GyroSensor{}
 
+// gyroAngleRange is the span of raw angles before the gyro overflows.
+const gyroAngleRange = 65536
+
 // Provides access to the EV3 gyro sensor
 type ev3GyroSensor struct {
-	sensor *ev3dev.Sensor
+	*sensorDevice
+
+	inverted int
+
+	// Held while reading or resetting the angle, as a SensorSampler and commands may read the gyro at once
+	angle     sync.Mutex
+	initAngle float64
 
-	initAngle   float64
-	inverted    int
-	currentMode gyroSensorMode
+	// Overflow tracking of the raw angle
+	lastRaw int
+	hasRaw  bool
+	wraps   int
 }
 
 // NewGyroSensor creates a new gyro sensor with the provided port.
 // Set inverted to true if arrow markings on the gyro are facing down.
+//
+// The gyro is always read in angle and rate mode, as switching modes resets the angle.
 func NewGyroSensor(port ev3lib.EV3Port, inverted bool) (*ev3lib.GyroSensor, error) {
-	sensor, err := ev3dev.SensorFor(string(port), gyroSensorDriverName)
+	sensor, err := findDevice(ev3dev.SensorPath, port, gyroSensorDriverName)
 	if err != nil {
 		return nil, err
 	}
 
-	sensor.SetMode(string(gyroSensorModeAngle))
-	s := &ev3GyroSensor{sensor: sensor, inverted: 1, initAngle: 0, currentMode: gyroSensorModeAngle}
+	s := &ev3GyroSensor{sensorDevice: newSensorDevice(sensor, port), inverted: 1}
 	if inverted {
 		s.inverted = -1
 	}
+	s.setMode(string(gyroSensorModeAngleRate))
 
 	return ev3lib.NewGyroSensorBase(s), nil
 }
 
+// rawAngle returns the angle read from the gyro, unwrapping overflows past -32768 and 32767.
+// s.angle must be held.
+func (s *ev3GyroSensor) rawAngle() (int, error) {
+	raw, err := s.value(string(gyroSensorModeAngleRate), 0)
+	if s.hasRaw {
+		if d := raw - s.lastRaw; d > gyroAngleRange/2 {
+			s.wraps--
+		} else if d < -gyroAngleRange/2 {
+			s.wraps++
+		}
+	}
+	s.lastRaw = raw
+	s.hasRaw = true
+
+	return raw + s.wraps*gyroAngleRange, err
+}
+
 // Rate returns the gyro's rotational speed in degrees per second.
 // Will max out at -440 and 440.
 func (s *ev3GyroSensor) Rate() float64 {
-	if s.currentMode != gyroSensorModeRate {
-		s.sensor.SetMode(string(gyroSensorModeRate))
-	}
-
-	val, err := s.sensor.Value(0)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	valInt, err := strconv.Atoi(val)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+	rate, _ := s.RateErr()
+	return rate
+}
 
-	return float64(valInt)
+func (s *ev3GyroSensor) RateErr() (float64, error) {
+	rate, err := s.value(string(gyroSensorModeAngleRate), 1)
+	return float64(rate * s.inverted), err
 }
 
 // Angle returns the current angle of the gyro in degrees.
-// The angle has a max cap from -32768 to 32767 degrees, depending on the manufacturer it will either freeze or overflow.
+// The gyro's angle is capped from -32768 to 32767 degrees, depending on the manufacturer it will either freeze or overflow.
+// Overflows are unwrapped, use ev3lib.HeadingTracker to also handle gyros which freeze.
 func (s *ev3GyroSensor) Angle() float64 {
-	if s.currentMode != gyroSensorModeAngle {
-		s.sensor.SetMode(string(gyroSensorModeAngle))
-	}
+	angle, _ := s.AngleErr()
+	return angle
+}
 
-	val, err := s.sensor.Value(0)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	valInt, err := strconv.Atoi(val)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+func (s *ev3GyroSensor) AngleErr() (float64, error) {
+	s.angle.Lock()
+	defer s.angle.Unlock()
 
-	return float64(valInt)*float64(s.inverted) - s.initAngle
+	raw, err := s.rawAngle()
+	return float64(raw*s.inverted) - s.initAngle, err
+}
+
+// RawAngle returns the angle last read from the gyro, before overflows are unwrapped, inversion and resets.
+// Used by ev3lib.HeadingTracker to detect a gyro frozen at its limit. Returns false until the angle has been read.
+func (s *ev3GyroSensor) RawAngle() (float64, bool) {
+	s.angle.Lock()
+	defer s.angle.Unlock()
+
+	return float64(s.lastRaw), s.hasRaw
 }
 
 // AngleRate returns both the angle and rate of the gyro, see Angle() and Rate() for more details
 func (s *ev3GyroSensor) AngleRate() (float64, float64) {
-	if s.currentMode != gyroSensorModeAngleRate {
-		s.sensor.SetMode(string(gyroSensorModeAngleRate))
-	}
-
-	val1, err := s.sensor.Value(0)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	angle, err := strconv.Atoi(val1)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+	angle, rate, _ := s.AngleRateErr()
+	return angle, rate
+}
 
-	val2, err := s.sensor.Value(1)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	rate, err := strconv.Atoi(val2)
-	if err != nil {
-		log.Fatal(err.Error())
+// AngleRateErr returns the angle and rate, and the first error reading them.
+func (s *ev3GyroSensor) AngleRateErr() (float64, float64, error) {
+	angle, err := s.AngleErr()
+	rate, rateErr := s.RateErr()
+	if err == nil {
+		err = rateErr
 	}
 
-	return float64(angle)*float64(s.inverted) - s.initAngle, float64(rate)
+	return angle, rate, err
 }
 
-// ResetAngle resets the current angle of the gyro.
+// ResetAngle sets the current angle of the gyro.
 func (s *ev3GyroSensor) ResetAngle(angle float64) {
-	s.initAngle = angle
+	s.angle.Lock()
+	defer s.angle.Unlock()
+
+	raw, _ := s.rawAngle()
+	s.initAngle = float64(raw*s.inverted) - angle
 }
 
-// Calibrate calibrates the gyro.
-// This function will block for around 200ms
+// Calibrate calibrates the gyro and resets its angle to 0.
+// This function blocks until the gyro reports a steady angle, for up to a second.
 // Ensure that the gyro is completely still during the calibration.
 func (s *ev3GyroSensor) Calibrate() {
-	s.sensor.SetMode(string(gyroSensorModeCalibrate))
-	time.Sleep(time.Millisecond * 100)
-	s.sensor.SetMode(string(gyroSensorModeAngle))
+	s.setMode(string(gyroSensorModeCalibrate))
 	time.Sleep(time.Millisecond * 100)
+	s.setMode(string(gyroSensorModeAngleRate))
+
+	// Wait for the angle to stop changing after the mode switch
+	last, _ := s.value(string(gyroSensorModeAngleRate), 0)
+	for i := 0; i < 10; i++ {
+		time.Sleep(time.Millisecond * 100)
+
+		angle, _ := s.value(string(gyroSensorModeAngleRate), 0)
+		if angle == last {
+			break
+		}
+		last = angle
+	}
+
+	s.angle.Lock()
+	defer s.angle.Unlock()
+
+	s.lastRaw = last
+	s.hasRaw = true
+	s.wraps = 0
+	s.initAngle = float64(last * s.inverted)
 }
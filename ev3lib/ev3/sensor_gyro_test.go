@@ -0,0 +1,120 @@
+//go:build !ev3test
+
+package ev3_test
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/Alanlu217/ev3lib/ev3lib/ev3"
+	"github.com/Alanlu217/ev3lib/ev3lib/testUtils"
+)
+
+func TestHeadingTrackerGyroLimits(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		inverted bool
+		// sampled tracks the gyro through a SensorSampler, which is not running so reads go straight to the gyro
+		sampled bool
+		// Raw angles reported every 10ms, with a rate of 100 deg/s
+		raw  []int
+		want float64
+	}{
+		{"overflow", false, false, []int{32760, 32765, 32767, -32766, -32760}, 16},
+		{"overflow inverted", true, false, []int{32760, 32765, 32767, -32766, -32760}, -16},
+		{"frozen", false, false, []int{32767, 32767, 32767, 32767, 32767}, 4},
+		{"frozen inverted", true, false, []int{-32768, -32768, -32768, -32768, -32768}, -4},
+		{"sampled overflow", false, true, []int{32760, 32765, 32767, -32766, -32760}, 16},
+		{"sampled frozen", false, true, []int{32767, 32767, 32767, 32767, 32767}, 4},
+		{"sampled frozen inverted", true, true, []int{-32768, -32768, -32768, -32768, -32768}, -4},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			prev := ev3lib.GetClock()
+			t.Cleanup(func() { ev3lib.SetClock(prev) })
+			c := testUtils.UseManualClock()
+
+			fake, err := newTree(t).AddGyroSensor(ev3lib.IN1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fake.SetValues(test.raw[0], 100)
+
+			gyro, err := ev3.NewGyroSensor(ev3lib.IN1, test.inverted)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Reset to a heading away from the limit, so only the raw reading shows the gyro is frozen
+			gyro.ResetAngle(90)
+
+			var tracked ev3lib.GyroSensorInterface = gyro
+			if test.sampled {
+				tracked = ev3lib.NewSensorSampler().GyroSensor(gyro, time.Hour)
+			}
+			tracker := ev3lib.NewHeadingTracker(tracked)
+			tracker.Reset(90)
+
+			for _, raw := range test.raw[1:] {
+				c.Step(10 * time.Millisecond)
+				fake.SetValues(raw, 100)
+				tracker.Update()
+			}
+
+			if got := tracker.Heading() - 90; math.Abs(got-test.want) > 1e-6 {
+				t.Errorf("heading changed by %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// TestGyroConcurrentReads reads and resets the gyro from a sampler, a heading tracker and a command at once, run it with -race.
+func TestGyroConcurrentReads(t *testing.T) {
+	fake, err := newTree(t).AddGyroSensor(ev3lib.IN1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.SetValues(32760, 0)
+
+	gyro, err := ev3.NewGyroSensor(ev3lib.IN1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if raw, ok := gyro.RawAngle(); ok {
+		t.Errorf("RawAngle before the first read = %v, true, want false", raw)
+	}
+
+	sampler := ev3lib.NewSensorSampler()
+	sampled := sampler.GyroSensor(gyro, time.Millisecond)
+	tracker := ev3lib.NewHeadingTracker(gyro)
+
+	sampler.Start()
+	defer sampler.Stop()
+
+	var wg sync.WaitGroup
+	for _, read := range []func(){
+		tracker.Update,
+		func() { gyro.ResetAngle(10) },
+		func() { sampled.AngleRate() },
+		func() { gyro.RawAngle() },
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				read()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if raw, ok := gyro.RawAngle(); !ok || raw != 32760 {
+		t.Errorf("RawAngle = %v, %v, want 32760, true", raw, ok)
+	}
+	if angle := gyro.Angle(); angle != 10 {
+		t.Errorf("Angle = %v, want the reset angle 10", angle)
+	}
+}
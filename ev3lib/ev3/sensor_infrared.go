@@ -4,8 +4,6 @@ package ev3
 
 import (
 	"fmt"
-	"log"
-	"strconv"
 
 	"github.com/Alanlu217/ev3lib/ev3lib"
 
@@ -29,40 +27,32 @@ var _ ev3lib.InfraredSensorInterface = &ev3InfraredSensor{}
 
 // Provides access to the EV3 infrared sensor.
 type ev3InfraredSensor struct {
-	sensor *ev3dev.Sensor
-
-	currentMode infraredSensorMode
+	*sensorDevice
 }
 
 // NewInfraredSensor creates a new infrared sensor from the provided port.
 func NewInfraredSensor(port ev3lib.EV3Port) (*ev3lib.InfraredSensor, error) {
-	sensor, err := ev3dev.SensorFor(string(port), infraredSensorDriverName)
+	sensor, err := findDevice(ev3dev.SensorPath, port, infraredSensorDriverName)
 	if err != nil {
 		return nil, err
 	}
 
-	sensor.SetMode(string(infraredSensorModeProximity))
-	return ev3lib.NewInfraredSensorBase(&ev3InfraredSensor{sensor: sensor, currentMode: infraredSensorModeProximity}), nil
+	s := &ev3InfraredSensor{sensorDevice: newSensorDevice(sensor, port)}
+	s.setMode(string(infraredSensorModeProximity))
+
+	return ev3lib.NewInfraredSensorBase(s), nil
 }
 
 // Distance returns the distance measured by the sensor from 0 to 1.
 func (s *ev3InfraredSensor) Distance() float64 {
-	if s.currentMode != infraredSensorModeProximity {
-		s.sensor.SetMode(string(infraredSensorModeProximity))
-	}
-
-	val, err := s.sensor.Value(0)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	valInt, err := strconv.Atoi(val)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+	distance, _ := s.DistanceErr()
+	return distance
+}
 
-	distance := float64(valInt) / 100
+func (s *ev3InfraredSensor) DistanceErr() (float64, error) {
+	distance, err := s.value(string(infraredSensorModeProximity), 0)
 
-	return distance
+	return float64(distance) / 100, err
 }
 
 var buttonMap = map[int][]ev3lib.BeaconButton{
@@ -83,23 +73,16 @@ var buttonMap = map[int][]ev3lib.BeaconButton{
 // Buttons returns a slice of BeaconButton's containing all the buttons that are currently being pressed.
 // Checks the buttons on the provided channel.
 func (s *ev3InfraredSensor) Buttons(channel int) []ev3lib.BeaconButton {
-	if s.currentMode != infraredSensorModeRemote {
-		s.sensor.SetMode(string(infraredSensorModeRemote))
-	}
+	buttons, _ := s.ButtonsErr(channel)
+	return buttons
+}
 
+func (s *ev3InfraredSensor) ButtonsErr(channel int) ([]ev3lib.BeaconButton, error) {
 	if channel < 0 || channel > 4 {
 		fmt.Println("Channel does not exist, only 0 to 4 are accepted")
-		return []ev3lib.BeaconButton{}
-	}
-
-	val, err := s.sensor.Value(channel)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	valInt, err := strconv.Atoi(val)
-	if err != nil {
-		log.Fatal(err.Error())
+		return []ev3lib.BeaconButton{}, fmt.Errorf("ev3: infrared channel %d does not exist", channel)
 	}
 
-	return buttonMap[valInt]
+	val, err := s.value(string(infraredSensorModeRemote), channel)
+	return buttonMap[val], err
 }
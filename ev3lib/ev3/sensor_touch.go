@@ -3,9 +3,6 @@
 package ev3
 
 import (
-	"log"
-	"strconv"
-
 	"github.com/Alanlu217/ev3lib/ev3lib"
 
 	"github.com/ev3go/ev3dev"
@@ -21,30 +18,29 @@ var _ ev3lib.TouchSensorInterface = &ev3TouchSensor{}
 
 // Provides access to a EV3 touch sensor.
 type ev3TouchSensor struct {
-	sensor *ev3dev.Sensor
+	*sensorDevice
 }
 
 // NewTouchSensor creates a new touch sensor on the provided port.
 func NewTouchSensor(port ev3lib.EV3Port) (*ev3lib.TouchSensor, error) {
-	sensor, err := ev3dev.SensorFor(string(port), touchSensorDriverName)
+	sensor, err := findDevice(ev3dev.SensorPath, port, touchSensorDriverName)
 	if err != nil {
 		return nil, err
 	}
 
-	sensor.SetMode("TOUCH")
-	return ev3lib.NewTouchSensorBase(&ev3TouchSensor{sensor: sensor}), nil
+	s := &ev3TouchSensor{sensorDevice: newSensorDevice(sensor, port)}
+	s.setMode("TOUCH")
+
+	return ev3lib.NewTouchSensorBase(s), nil
 }
 
 // IsPressed returns whether the button is currently being pressed.
 func (s *ev3TouchSensor) IsPressed() bool {
-	val, err := s.sensor.Value(0)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	valInt, err := strconv.Atoi(val)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+	pressed, _ := s.IsPressedErr()
+	return pressed
+}
 
-	return valInt == 1
+func (s *ev3TouchSensor) IsPressedErr() (bool, error) {
+	val, err := s.value("TOUCH", 0)
+	return val == 1, err
 }
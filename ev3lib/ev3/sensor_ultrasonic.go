@@ -3,9 +3,6 @@
 package ev3
 
 import (
-	"log"
-	"strconv"
-
 	"github.com/Alanlu217/ev3lib/ev3lib"
 
 	"github.com/ev3go/ev3dev"
@@ -29,72 +26,51 @@ var _ ev3lib.UltrasonicSensorInterface = &ev3UltrasonicSensor{}
 
 // Provides access to an EV3 ultrasonic sensor.
 type ev3UltrasonicSensor struct {
-	sensor *ev3dev.Sensor
-
-	currentMode ultrasonicSensorMode
+	*sensorDevice
 }
 
 // NewUltrasonicSensor creates a new ultrasonic sensor on the provided port.
 func NewUltrasonicSensor(port ev3lib.EV3Port) (*ev3lib.UltrasonicSensor, error) {
-	sensor, err := ev3dev.SensorFor(string(port), ultrasonicSensorDriverName)
+	sensor, err := findDevice(ev3dev.SensorPath, port, ultrasonicSensorDriverName)
 	if err != nil {
 		return nil, err
 	}
 
-	sensor.SetMode(string(ultrasonicSensorModeProximity))
-	return ev3lib.NewUltrasonicSensorBase(&ev3UltrasonicSensor{sensor: sensor, currentMode: ultrasonicSensorModeProximity}), nil
+	s := &ev3UltrasonicSensor{sensorDevice: newSensorDevice(sensor, port)}
+	s.setMode(string(ultrasonicSensorModeProximity))
+
+	return ev3lib.NewUltrasonicSensorBase(s), nil
 }
 
 // Distance returns the measured distance in centimeters from 0 to 2550.
 func (s *ev3UltrasonicSensor) Distance() float64 {
-	if s.currentMode != ultrasonicSensorModeProximity {
-		s.sensor.SetMode(string(ultrasonicSensorModeProximity))
-	}
-
-	val, err := s.sensor.Value(0)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	valInt, err := strconv.Atoi(val)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+	distance, _ := s.DistanceErr()
+	return distance
+}
 
-	return float64(valInt)
+func (s *ev3UltrasonicSensor) DistanceErr() (float64, error) {
+	distance, err := s.value(string(ultrasonicSensorModeProximity), 0)
+	return float64(distance), err
 }
 
 // DistanceSilent same as Distance(), but will turn sensor off after measurement.
 func (s *ev3UltrasonicSensor) DistanceSilent() float64 {
-	if s.currentMode != ultrasonicSensorModeSilentProximity {
-		s.sensor.SetMode(string(ultrasonicSensorModeSilentProximity))
-	}
-
-	val, err := s.sensor.Value(0)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	valInt, err := strconv.Atoi(val)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+	distance, _ := s.DistanceSilentErr()
+	return distance
+}
 
-	return float64(valInt)
+func (s *ev3UltrasonicSensor) DistanceSilentErr() (float64, error) {
+	distance, err := s.value(string(ultrasonicSensorModeSilentProximity), 0)
+	return float64(distance), err
 }
 
 // Presence listens for the presence of other ultrasonic sensors.
 func (s *ev3UltrasonicSensor) Presence() bool {
-	if s.currentMode != ultrasonicSensorModeListen {
-		s.sensor.SetMode(string(ultrasonicSensorModeListen))
-	}
-
-	val, err := s.sensor.Value(0)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	valInt, err := strconv.Atoi(val)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+	presence, _ := s.PresenceErr()
+	return presence
+}
 
-	return valInt == 1
+func (s *ev3UltrasonicSensor) PresenceErr() (bool, error) {
+	val, err := s.value(string(ultrasonicSensorModeListen), 0)
+	return val == 1, err
 }
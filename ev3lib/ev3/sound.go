@@ -0,0 +1,229 @@
+//go:build !ev3test
+
+package ev3
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/ev3go/ev3dev"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Sound Player                                                               //
+////////////////////////////////////////////////////////////////////////////////
+
+// SpeakerPath is the ev3dev sound event device tones are played on.
+var SpeakerPath = "/dev/input/by-path/platform-sound-event"
+
+// soundPlayer plays one sound at a time in the background, tones through the speaker and PCM audio through aplay.
+type soundPlayer struct {
+	m sync.Mutex
+
+	speaker *ev3dev.Speaker
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// play stops the current sound and starts playing a new one, which should return when stop is closed.
+func (p *soundPlayer) play(name string, f func(stop <-chan struct{}) error) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	p.stopLocked()
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	p.stop, p.done = stop, done
+
+	go func() {
+		defer close(done)
+
+		if err := f(stop); err != nil {
+			log.Printf("ev3: failed to play %v: %v\n", name, err)
+		}
+	}()
+}
+
+func (p *soundPlayer) stopLocked() {
+	if p.stop == nil {
+		return
+	}
+
+	close(p.stop)
+	<-p.done
+	p.stop, p.done = nil, nil
+}
+
+// Stop stops the current sound and waits for it to finish.
+func (p *soundPlayer) Stop() {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	p.stopLocked()
+}
+
+func (p *soundPlayer) IsPlaying() bool {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if p.done == nil {
+		return false
+	}
+
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// wait waits for a duration, returning false if the sound was stopped first.
+func wait(stop <-chan struct{}, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-stop:
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// tone plays a tone until it is changed, or stops it if the frequency is 0.
+func (p *soundPlayer) tone(frequency float64) error {
+	if p.speaker == nil {
+		speaker := ev3dev.NewSpeaker(SpeakerPath)
+		if err := speaker.Init(); err != nil {
+			return err
+		}
+		p.speaker = speaker
+	}
+
+	return p.speaker.Tone(uint32(frequency))
+}
+
+// run runs a program until it exits or the sound is stopped.
+func run(stop <-chan struct{}, cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	select {
+	case <-stop:
+		cmd.Process.Kill()
+		<-exited
+		return nil
+	case err := <-exited:
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// EV3Brick Sound                                                             //
+////////////////////////////////////////////////////////////////////////////////
+
+func (e *ev3) Beep(frequency float64, duration float64) {
+	e.sound.play("beep", func(stop <-chan struct{}) error {
+		if err := e.sound.tone(frequency); err != nil {
+			return err
+		}
+		wait(stop, time.Duration(duration*float64(time.Second)))
+		return e.sound.tone(0)
+	})
+}
+
+func (e *ev3) PlayNotes(notes []ev3lib.EV3Note, tempo float64) {
+	parsed, err := ev3lib.ParseNotes(notes)
+	if err != nil {
+		log.Printf("ev3: failed to play notes: %v\n", err)
+		return
+	}
+
+	e.sound.play("notes", func(stop <-chan struct{}) error {
+		defer e.sound.tone(0)
+
+		for _, note := range parsed {
+			sound, gap := note.Timing(tempo)
+
+			if note.Frequency != 0 {
+				if err := e.sound.tone(note.Frequency); err != nil {
+					return err
+				}
+				if !wait(stop, sound) {
+					return nil
+				}
+				if err := e.sound.tone(0); err != nil {
+					return err
+				}
+			}
+
+			if !wait(stop, gap) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// SpeakText speaks text using the speech backend set with ev3lib.SetSpeechBackend.
+func (e *ev3) SpeakText(text string) {
+	e.sound.play(fmt.Sprintf("speech %q", text), func(stop <-chan struct{}) error {
+		wav, err := ev3lib.GetSpeechBackend().Synthesize(text)
+		if err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		if err := wav.Write(&b); err != nil {
+			return err
+		}
+
+		cmd := exec.Command("aplay", "-q", "-")
+		cmd.Stdin = &b
+		return run(stop, cmd)
+	})
+}
+
+func (e *ev3) PlayWAV(path string) {
+	e.sound.play(path, func(stop <-chan struct{}) error {
+		return run(stop, exec.Command("aplay", "-q", path))
+	})
+}
+
+func (e *ev3) StopSound() {
+	e.sound.Stop()
+}
+
+func (e *ev3) IsSoundPlaying() bool {
+	return e.sound.IsPlaying()
+}
+
+// SetVolume sets the volume of both tones and PCM audio.
+func (e *ev3) SetVolume(volume float64) {
+	percent := fmt.Sprintf("%d%%", int(ev3lib.Clamp(volume, 0, 1)*100))
+
+	for _, control := range []string{"Beep", "PCM"} {
+		if out, err := exec.Command("amixer", "-q", "set", control, percent).CombinedOutput(); err != nil {
+			log.Printf("ev3: failed to set %v volume: %v: %s\n", control, err, strings.TrimSpace(string(out)))
+		}
+	}
+}
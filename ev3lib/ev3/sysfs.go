@@ -0,0 +1,176 @@
+//go:build !ev3test
+
+package ev3
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Sysfs Root                                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+var (
+	sysfsLock sync.Mutex
+	sysfsRoot string
+
+	// portsInUse holds the device opened on each port of a device class, so that a port can only be opened once
+	portsInUse = map[string]*sysfsDevice{}
+)
+
+// SetSysfsRoot sets the directory that device classes, such as /sys/class/tacho-motor, are found in.
+// It is empty on the brick, and is set by the fakeSysfs package to run the drivers on a PC.
+// Devices keep using the root they were created with.
+func SetSysfsRoot(root string) {
+	sysfsLock.Lock()
+	defer sysfsLock.Unlock()
+
+	sysfsRoot = root
+}
+
+// SysfsRoot returns the directory that device classes are found in.
+func SysfsRoot() string {
+	sysfsLock.Lock()
+	defer sysfsLock.Unlock()
+
+	return sysfsRoot
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Sysfs Device                                                               //
+////////////////////////////////////////////////////////////////////////////////
+
+// sysfsDevice is a directory of attribute files, such as /sys/class/lego-sensor/sensor0.
+type sysfsDevice struct {
+	path string
+
+	// Attribute files are kept open after the first read, as opening them is slow on the brick
+	m     sync.Mutex
+	files map[string]*os.File
+}
+
+// newSysfsDevice returns the device with a name in a class, such as ev3dev.LEDPath.
+func newSysfsDevice(class, name string) *sysfsDevice {
+	return &sysfsDevice{path: filepath.Join(SysfsRoot(), class, name), files: map[string]*os.File{}}
+}
+
+// findDevices returns every device in a class, such as ev3dev.SensorPath.
+func findDevices(class string) ([]*sysfsDevice, error) {
+	entries, err := os.ReadDir(filepath.Join(SysfsRoot(), class))
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]*sysfsDevice, 0, len(entries))
+	for _, e := range entries {
+		devices = append(devices, newSysfsDevice(class, e.Name()))
+	}
+	return devices, nil
+}
+
+// findDevice returns the device in a class which is connected to a port, checking that it uses the expected driver.
+func findDevice(class string, port ev3lib.EV3Port, driver string) (*sysfsDevice, error) {
+	devices, err := findDevices(class)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range devices {
+		if address, err := d.attribute("address"); err != nil || address != string(port) {
+			continue
+		}
+
+		name, err := d.attribute("driver_name")
+		if err != nil {
+			return nil, err
+		}
+		if name != driver {
+			return nil, fmt.Errorf("ev3: found %v on %v, expected %v", name, port, driver)
+		}
+		if err := claimPort(port, d); err != nil {
+			return nil, err
+		}
+		return d, nil
+	}
+
+	return nil, fmt.Errorf("ev3: could not find %v on %v", driver, port)
+}
+
+// claimPort marks the port of a device as in use.
+// It fails if a device opened earlier is still connected to the port, as two drivers would fight over it.
+func claimPort(port ev3lib.EV3Port, d *sysfsDevice) error {
+	sysfsLock.Lock()
+	defer sysfsLock.Unlock()
+
+	// Keyed by the class directory, so ports are separate for motors and sensors, and for each sysfs root
+	key := filepath.Join(filepath.Dir(d.path), string(port))
+
+	// The address is read from a new file, as the attached device may have been unplugged
+	if attached, found := portsInUse[key]; found {
+		address, err := os.ReadFile(filepath.Join(attached.path, "address"))
+		if err == nil && strings.TrimSuffix(string(address), "\n") == string(port) {
+			return fmt.Errorf("ev3: port %v in use", port)
+		}
+	}
+
+	portsInUse[key] = d
+	return nil
+}
+
+// attribute returns the value of an attribute without the trailing newline.
+func (d *sysfsDevice) attribute(name string) (string, error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	f, ok := d.files[name]
+	if !ok {
+		var err error
+		if f, err = os.Open(filepath.Join(d.path, name)); err != nil {
+			return "", err
+		}
+		d.files[name] = f
+	}
+
+	// Attributes are at most a page long
+	var buf [4096]byte
+	n, err := f.ReadAt(buf[:], 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		f.Close()
+		delete(d.files, name)
+		return "", err
+	}
+
+	return strings.TrimSuffix(string(buf[:n]), "\n"), nil
+}
+
+// intAttribute returns the value of an integer attribute.
+func (d *sysfsDevice) intAttribute(name string) (int, error) {
+	val, err := d.attribute(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(val)
+}
+
+// setAttribute writes the value of an attribute. It is an error if the attribute does not exist.
+func (d *sysfsDevice) setAttribute(name, value string) error {
+	f, err := os.OpenFile(filepath.Join(d.path, name), os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.WriteString(value)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
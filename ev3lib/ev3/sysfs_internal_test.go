@@ -0,0 +1,32 @@
+//go:build !ev3test
+
+package ev3
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetAttribute(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "command"), []byte("run-direct\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &sysfsDevice{path: dir, files: map[string]*os.File{}}
+
+	if err := d.setAttribute("command", "stop"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := d.attribute("command"); got != "stop" || err != nil {
+		t.Errorf("command = %q, %v, want stop", got, err)
+	}
+
+	if err := d.setAttribute("comand", "stop"); err == nil {
+		t.Error("wrote a missing attribute without an error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "comand")); !os.IsNotExist(err) {
+		t.Errorf("writing a missing attribute created it: %v", err)
+	}
+}
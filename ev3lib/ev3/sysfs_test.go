@@ -0,0 +1,198 @@
+//go:build !ev3test
+
+package ev3_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/Alanlu217/ev3lib/ev3lib/ev3"
+	"github.com/Alanlu217/ev3lib/ev3lib/ev3/fakeSysfs"
+)
+
+func newTree(t *testing.T) *fakeSysfs.Tree {
+	tree, err := fakeSysfs.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(tree.Close)
+
+	return tree
+}
+
+func TestMotor(t *testing.T) {
+	tree := newTree(t)
+
+	fake, err := tree.AddLargeMotor(ev3lib.OUTA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := ev3.NewLargeMotor(ev3lib.OUTA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.Set(0.5)
+	if got := fake.DutyCycleSetpoint(); got != 50 {
+		t.Errorf("duty cycle setpoint = %d, want 50", got)
+	}
+	if got := fake.Command(); got != "run-direct" {
+		t.Errorf("command = %q, want run-direct", got)
+	}
+
+	m.Stop()
+	if got := fake.Command(); got != "stop" {
+		t.Errorf("command = %q, want stop", got)
+	}
+
+	m.SetScale(0.5)
+	fake.SetPosition(720)
+	if got := m.Position(); got != 360 {
+		t.Errorf("position = %v, want 360", got)
+	}
+
+	m.ResetPosition(10)
+	if got := fake.Position(); got != 20 {
+		t.Errorf("fake position = %d, want 20", got)
+	}
+
+	fake.SetState("running", "stalled")
+	if got := m.State(); got != ev3lib.Running|ev3lib.Stalled {
+		t.Errorf("state = %v, want running and stalled", got)
+	}
+
+	m.SetInverted(true)
+	if got := fake.Polarity(); got != "inversed" || !m.Inverted() {
+		t.Errorf("polarity = %q, want inversed", got)
+	}
+
+	if got := m.CountPerRot(); got != 360 {
+		t.Errorf("count per rotation = %d, want 360", got)
+	}
+}
+
+func TestFindDevice(t *testing.T) {
+	tree := newTree(t)
+
+	if _, err := tree.AddLargeMotor(ev3lib.OUTA); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tree.AddTouchSensor(ev3lib.IN1); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name string
+		open func() error
+		ok   bool
+	}{
+		{"large motor", func() error { _, err := ev3.NewLargeMotor(ev3lib.OUTA); return err }, true},
+		{"large motor twice", func() error { _, err := ev3.NewLargeMotor(ev3lib.OUTA); return err }, false},
+		{"wrong driver", func() error { _, err := ev3.NewMediumMotor(ev3lib.OUTA); return err }, false},
+		{"empty port", func() error { _, err := ev3.NewLargeMotor(ev3lib.OUTB); return err }, false},
+		{"touch sensor", func() error { _, err := ev3.NewTouchSensor(ev3lib.IN1); return err }, true},
+		{"wrong sensor", func() error { _, err := ev3.NewGyroSensor(ev3lib.IN1, false); return err }, false},
+	} {
+		if err := test.open(); (err == nil) != test.ok {
+			t.Errorf("%v: err = %v, want ok %v", test.name, err, test.ok)
+		}
+	}
+}
+
+func TestPortInUse(t *testing.T) {
+	tree := newTree(t)
+
+	fake, err := tree.AddTouchSensor(ev3lib.IN1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ev3.NewTouchSensor(ev3lib.IN1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ev3.NewTouchSensor(ev3lib.IN1); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Errorf("opening a port twice: err = %v, want port in use", err)
+	}
+
+	// Motors and sensors have separate ports
+	if _, err := tree.AddLargeMotor(ev3lib.OUTA); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ev3.NewLargeMotor(ev3lib.OUTA); err != nil {
+		t.Errorf("opening a motor: %v", err)
+	}
+
+	// Unplugging the sensor frees the port for the sensor plugged in next
+	if err := os.RemoveAll(fake.Path()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tree.AddTouchSensor(ev3lib.IN1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ev3.NewTouchSensor(ev3lib.IN1); err != nil {
+		t.Errorf("opening a port after the sensor was replaced: %v", err)
+	}
+}
+
+func TestSensorMode(t *testing.T) {
+	tree := newTree(t)
+
+	fake, err := tree.AddUltrasonicSensor(ev3lib.IN2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := ev3.NewUltrasonicSensor(ev3lib.IN2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake.SetValues(1)
+	if !s.Presence() {
+		t.Error("presence not detected")
+	}
+	if got := fake.Mode(); got != "US-LISTEN" {
+		t.Errorf("mode = %q, want US-LISTEN", got)
+	}
+
+	fake.SetValues(123)
+	if got := s.Distance(); got != 123 {
+		t.Errorf("distance = %v, want 123", got)
+	}
+	if got := fake.Mode(); got != "US-DIST-CM" {
+		t.Errorf("mode = %q, want US-DIST-CM", got)
+	}
+}
+
+func TestSensorDisconnect(t *testing.T) {
+	tree := newTree(t)
+
+	fake, err := tree.AddTouchSensor(ev3lib.IN3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := ev3.NewTouchSensor(ev3lib.IN3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake.SetValues(1)
+	if pressed, err := s.IsPressedErr(); !pressed || err != nil {
+		t.Fatalf("IsPressedErr() = %v, %v, want true, nil", pressed, err)
+	}
+
+	// The last good value is returned while disconnected
+	fake.Disconnect()
+	if pressed, err := s.IsPressedErr(); !pressed || err == nil {
+		t.Errorf("IsPressedErr() = %v, %v, want true and an error", pressed, err)
+	}
+
+	fake.SetValues(0)
+	if pressed, err := s.IsPressedErr(); pressed || err != nil {
+		t.Errorf("IsPressedErr() = %v, %v after reconnecting, want false, nil", pressed, err)
+	}
+}
@@ -1,5 +1,7 @@
 package ev3lib
 
+import "io"
+
 ////////////////////////////////////////////////////////////////////////////////
 // EV3Brick interface                                                         //
 ////////////////////////////////////////////////////////////////////////////////
@@ -11,20 +13,42 @@ type EV3BrickInterface interface {
 	IsButtonUp(button EV3Button) bool
 	ButtonsPressed() []EV3Button
 
+	// SetLight sets both status lights, stopping any pattern.
 	SetLight(color EV3Color)
 
+	// SetLED sets one status light, stopping any pattern.
+	SetLED(led EV3LED, color EV3Color)
+
+	// PlayLightPattern repeats a pattern on both status lights in the background.
+	PlayLightPattern(pattern LightPattern)
+
+	// Sounds play in the background, replacing any sound already playing.
+	// Durations are in seconds, tempos in quarter notes per minute and volumes from 0 to 1.
+
 	Beep(frequency, duration float64)
 
 	PlayNotes(notes []EV3Note, tempo float64)
 
+	SpeakText(text string)
+
+	PlayWAV(path string)
+
+	StopSound()
+
+	IsSoundPlaying() bool
+
 	SetVolume(volume float64)
 
 	ClearScreen()
 
 	DrawText(x, y int, text string)
 
+	// PrintScreen prints each string on its own line of the console.
 	PrintScreen(text ...string)
 
+	// Console returns a writer to the text console, for fmt.Fprintf or log.SetOutput.
+	Console() io.Writer
+
 	DrawPixel(x, y int, black bool)
 
 	Voltage() float64
@@ -55,6 +79,12 @@ type MainMenuInterface interface {
 	Display(menu *Menu, command, page int, running bool)
 }
 
+// MenuStatusInterface is optionally implemented by a MainMenuInterface to show the menu status, e.g. on the status lights.
+// It is separate so that existing MainMenuInterface implementations keep working without it.
+type MenuStatusInterface interface {
+	SetStatus(status MenuStatus)
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // MotorInterface Interface                                                   //
 ////////////////////////////////////////////////////////////////////////////////
@@ -89,6 +119,12 @@ type ColorSensorInterface interface {
 	GetRGB() (float64, float64, float64)
 }
 
+// ColorDetectorInterface is optionally implemented by a ColorSensorInterface which detects LEGO colors itself, e.g. the EV3 color mode.
+// It is separate so that existing ColorSensorInterface implementations keep working without it.
+type ColorDetectorInterface interface {
+	Color() LegoColor
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Gyro Sensor Interface                                                      //
 ////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,114 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Bitmap                                                                     //
+////////////////////////////////////////////////////////////////////////////////
+
+// Bitmap is a 1-bit image, which can be drawn on as a Canvas or used as an image.Image.
+type Bitmap struct {
+	Rect image.Rectangle
+
+	// Pix holds whether each pixel is black, row by row.
+	Pix []bool
+}
+
+var (
+	_ Canvas      = &Bitmap{}
+	_ image.Image = &Bitmap{}
+)
+
+// NewBitmap creates a white bitmap.
+func NewBitmap(r image.Rectangle) *Bitmap {
+	return &Bitmap{Rect: r, Pix: make([]bool, r.Dx()*r.Dy())}
+}
+
+// NewBitmapFromImage converts an image to a bitmap, where pixels darker than a threshold from 0 to 1 are black.
+// Pixels that are more than half transparent are white.
+func NewBitmapFromImage(img image.Image, threshold float64) *Bitmap {
+	b := NewBitmap(img.Bounds())
+	for y := b.Rect.Min.Y; y < b.Rect.Max.Y; y++ {
+		for x := b.Rect.Min.X; x < b.Rect.Max.X; x++ {
+			gray := color.Gray16Model.Convert(img.At(x, y)).(color.Gray16)
+			_, _, _, a := img.At(x, y).RGBA()
+
+			// Gray is premultiplied by alpha, so compare against the threshold of the opacity
+			b.SetPixel(x, y, a >= 0x8000 && float64(gray.Y) < threshold*float64(a))
+		}
+	}
+	return b
+}
+
+// DecodePNG reads a PNG as a bitmap, see NewBitmapFromImage.
+func DecodePNG(r io.Reader, threshold float64) (*Bitmap, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewBitmapFromImage(img, threshold), nil
+}
+
+// LoadPNG reads a PNG file as a bitmap, see NewBitmapFromImage.
+func LoadPNG(path string, threshold float64) (*Bitmap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return DecodePNG(f, threshold)
+}
+
+func (b *Bitmap) index(x, y int) int {
+	return (y-b.Rect.Min.Y)*b.Rect.Dx() + x - b.Rect.Min.X
+}
+
+func (b *Bitmap) Bounds() image.Rectangle {
+	return b.Rect
+}
+
+func (b *Bitmap) Pixel(x, y int) bool {
+	if !image.Pt(x, y).In(b.Rect) {
+		return false
+	}
+	return b.Pix[b.index(x, y)]
+}
+
+func (b *Bitmap) SetPixel(x, y int, black bool) {
+	if image.Pt(x, y).In(b.Rect) {
+		b.Pix[b.index(x, y)] = black
+	}
+}
+
+func (b *Bitmap) ColorModel() color.Model {
+	return color.GrayModel
+}
+
+func (b *Bitmap) At(x, y int) color.Color {
+	if b.Pixel(x, y) {
+		return color.Black
+	}
+	return color.White
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Blitting                                                                   //
+////////////////////////////////////////////////////////////////////////////////
+
+// DrawBitmap draws the black pixels of a bitmap with its top left at a point, leaving its white pixels transparent.
+func (g *Graphics) DrawBitmap(x, y int, b *Bitmap) {
+	for by := b.Rect.Min.Y; by < b.Rect.Max.Y; by++ {
+		for bx := b.Rect.Min.X; bx < b.Rect.Max.X; bx++ {
+			if b.Pix[b.index(bx, by)] {
+				g.Plot(x+bx-b.Rect.Min.X, y+by-b.Rect.Min.Y)
+			}
+		}
+	}
+}
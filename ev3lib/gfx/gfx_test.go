@@ -0,0 +1,216 @@
+package gfx_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Alanlu217/ev3lib/ev3lib/gfx"
+)
+
+// picture returns a bitmap as rows of # for black and . for white.
+func picture(b *gfx.Bitmap) []string {
+	var rows []string
+	for y := b.Rect.Min.Y; y < b.Rect.Max.Y; y++ {
+		var row strings.Builder
+		for x := b.Rect.Min.X; x < b.Rect.Max.X; x++ {
+			if b.Pixel(x, y) {
+				row.WriteByte('#')
+			} else {
+				row.WriteByte('.')
+			}
+		}
+		rows = append(rows, row.String())
+	}
+	return rows
+}
+
+func TestShapes(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		draw func(g *gfx.Graphics)
+		want []string
+	}{
+		{
+			"shallow line",
+			func(g *gfx.Graphics) { g.Line(0, 0, 4, 2) },
+			[]string{
+				"#....",
+				".##..",
+				"...##",
+				".....",
+				".....",
+			},
+		},
+		{
+			"steep line backwards",
+			func(g *gfx.Graphics) { g.Line(2, 4, 0, 0) },
+			[]string{
+				"#....",
+				"#....",
+				".#...",
+				".#...",
+				"..#..",
+			},
+		},
+		{
+			"single point line",
+			func(g *gfx.Graphics) { g.Line(2, 2, 2, 2) },
+			[]string{
+				".....",
+				".....",
+				"..#..",
+				".....",
+				".....",
+			},
+		},
+		{
+			"circle",
+			func(g *gfx.Graphics) { g.Circle(2, 2, 2) },
+			[]string{
+				".###.",
+				"#...#",
+				"#...#",
+				"#...#",
+				".###.",
+			},
+		},
+		{
+			"inverted circle draws overlapping points once",
+			func(g *gfx.Graphics) { g.SetMode(gfx.Invert).Circle(2, 2, 2) },
+			[]string{
+				".###.",
+				"#...#",
+				"#...#",
+				"#...#",
+				".###.",
+			},
+		},
+		{
+			"filled circle",
+			func(g *gfx.Graphics) { g.FillCircle(2, 2, 2) },
+			[]string{
+				".###.",
+				"#####",
+				"#####",
+				"#####",
+				".###.",
+			},
+		},
+		{
+			"quarter arc",
+			func(g *gfx.Graphics) { g.Arc(2, 2, 2, 0, 90) },
+			[]string{
+				".....",
+				".....",
+				"....#",
+				"....#",
+				"..##.",
+			},
+		},
+		{
+			"arc across 0 degrees",
+			func(g *gfx.Graphics) { g.Arc(2, 2, 2, 315, 405) },
+			[]string{
+				".....",
+				"....#",
+				"....#",
+				"....#",
+				".....",
+			},
+		},
+		{
+			"rect",
+			func(g *gfx.Graphics) { g.Rect(image.Rect(0, 1, 4, 4)) },
+			[]string{
+				".....",
+				"####.",
+				"#..#.",
+				"####.",
+				".....",
+			},
+		},
+		{
+			"clipped fill",
+			func(g *gfx.Graphics) { g.SetClip(image.Rect(1, 1, 4, 4)).FillRect(image.Rect(-10, -10, 10, 10)) },
+			[]string{
+				".....",
+				".###.",
+				".###.",
+				".###.",
+				".....",
+			},
+		},
+		{
+			"clipped line",
+			func(g *gfx.Graphics) { g.SetClip(image.Rect(0, 2, 5, 5)).Line(0, 0, 4, 4) },
+			[]string{
+				".....",
+				".....",
+				"..#..",
+				"...#.",
+				"....#",
+			},
+		},
+		{
+			"off the canvas",
+			func(g *gfx.Graphics) { g.Line(-5, 2, 10, 2) },
+			[]string{
+				".....",
+				".....",
+				"#####",
+				".....",
+				".....",
+			},
+		},
+	} {
+		b := gfx.NewBitmap(image.Rect(0, 0, 5, 5))
+		test.draw(gfx.New(b))
+
+		if got := picture(b); !slices.Equal(got, test.want) {
+			t.Errorf("%v: drew\n%v\nwant\n%v", test.name, strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+		}
+	}
+}
+
+func TestDecodePNG(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 5, 1))
+	img.Set(0, 0, color.NRGBA{0, 0, 0, 255})
+	img.Set(1, 0, color.NRGBA{100, 100, 100, 255})
+	img.Set(2, 0, color.NRGBA{200, 200, 200, 255})
+	img.Set(3, 0, color.NRGBA{255, 255, 255, 255})
+	img.Set(4, 0, color.NRGBA{0, 0, 0, 50})
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	for _, test := range []struct {
+		threshold float64
+		want      string
+	}{
+		{0, "....."},
+		{0.5, "##..."},
+		{0.9, "###.."},
+		{1.01, "####."},
+	} {
+		b, err := gfx.DecodePNG(bytes.NewReader(data), test.threshold)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := picture(b)[0]; got != test.want {
+			t.Errorf("threshold %v: decoded %v, want %v", test.threshold, got, test.want)
+		}
+	}
+
+	if _, err := gfx.DecodePNG(strings.NewReader("not a png"), 0.5); err == nil {
+		t.Error("decoded an invalid PNG without an error")
+	}
+}
@@ -0,0 +1,155 @@
+// Package gfx draws lines, shapes, images, text and widgets such as bars and gauges on a 1-bit canvas,
+// like the EV3 LCD or an off-screen Bitmap.
+//
+// Coordinates start at the top left with y increasing down, and angles are in degrees clockwise from the positive x axis.
+package gfx
+
+import "image"
+
+////////////////////////////////////////////////////////////////////////////////
+// Canvas                                                                     //
+////////////////////////////////////////////////////////////////////////////////
+
+// Canvas is a surface of black and white pixels.
+type Canvas interface {
+	Bounds() image.Rectangle
+
+	// Pixel returns whether a pixel is black, or false outside the bounds.
+	Pixel(x, y int) bool
+
+	// SetPixel sets a pixel to black or white, ignoring pixels outside the bounds.
+	SetPixel(x, y int, black bool)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Draw Mode                                                                  //
+////////////////////////////////////////////////////////////////////////////////
+
+// DrawMode is how drawn pixels change the canvas.
+type DrawMode int
+
+const (
+	// Black draws black pixels.
+	Black DrawMode = iota
+
+	// White draws white pixels, erasing.
+	White
+
+	// Invert flips pixels, so drawing the same shape twice restores what was underneath.
+	Invert
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Graphics                                                                   //
+////////////////////////////////////////////////////////////////////////////////
+
+// Graphics draws on a canvas, clipped to a rectangle, with a draw mode and font.
+type Graphics struct {
+	canvas Canvas
+
+	clip image.Rectangle
+	mode DrawMode
+	font Font
+}
+
+// New creates graphics for a canvas, drawing black with no font and clipped to the canvas.
+func New(canvas Canvas) *Graphics {
+	return &Graphics{canvas: canvas, clip: canvas.Bounds()}
+}
+
+// Canvas returns the canvas being drawn on.
+func (g *Graphics) Canvas() Canvas {
+	return g.canvas
+}
+
+// SetClip limits drawing to a rectangle within the canvas.
+func (g *Graphics) SetClip(r image.Rectangle) *Graphics {
+	g.clip = r.Intersect(g.canvas.Bounds())
+	return g
+}
+
+// ClearClip allows drawing on the whole canvas.
+func (g *Graphics) ClearClip() *Graphics {
+	g.clip = g.canvas.Bounds()
+	return g
+}
+
+// Clip returns the rectangle drawing is limited to.
+func (g *Graphics) Clip() image.Rectangle {
+	return g.clip
+}
+
+func (g *Graphics) SetMode(mode DrawMode) *Graphics {
+	g.mode = mode
+	return g
+}
+
+func (g *Graphics) Mode() DrawMode {
+	return g.mode
+}
+
+func (g *Graphics) SetFont(font Font) *Graphics {
+	g.font = font
+	return g
+}
+
+func (g *Graphics) Font() Font {
+	return g.font
+}
+
+// Plot draws a pixel using the draw mode, if it is within the clip.
+func (g *Graphics) Plot(x, y int) {
+	if !image.Pt(x, y).In(g.clip) {
+		return
+	}
+
+	switch g.mode {
+	case Black:
+		g.canvas.SetPixel(x, y, true)
+	case White:
+		g.canvas.SetPixel(x, y, false)
+	case Invert:
+		g.canvas.SetPixel(x, y, !g.canvas.Pixel(x, y))
+	}
+}
+
+// plotSpan draws a horizontal line from x0 to x1 inclusive.
+func (g *Graphics) plotSpan(x0, x1, y int) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	if y < g.clip.Min.Y || y >= g.clip.Max.Y {
+		return
+	}
+
+	for x := max(x0, g.clip.Min.X); x <= min(x1, g.clip.Max.X-1); x++ {
+		g.Plot(x, y)
+	}
+}
+
+// plotPoints draws each distinct point once, so overlapping points are not inverted twice.
+func (g *Graphics) plotPoints(points []image.Point) {
+	if g.mode != Invert {
+		for _, p := range points {
+			g.Plot(p.X, p.Y)
+		}
+		return
+	}
+
+	seen := make(map[image.Point]bool, len(points))
+	for _, p := range points {
+		if !seen[p] {
+			seen[p] = true
+			g.Plot(p.X, p.Y)
+		}
+	}
+}
+
+// Clear sets every pixel in the clip to white, whatever the draw mode.
+func (g *Graphics) Clear() {
+	for y := g.clip.Min.Y; y < g.clip.Max.Y; y++ {
+		for x := g.clip.Min.X; x < g.clip.Max.X; x++ {
+			g.canvas.SetPixel(x, y, false)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package gfx
+
+import (
+	"image"
+	"math"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Lines                                                                      //
+////////////////////////////////////////////////////////////////////////////////
+
+// linePoints returns the points of a line from Bresenham's algorithm, including both ends.
+func linePoints(x0, y0, x1, y1 int) []image.Point {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	e := dx + dy
+
+	points := make([]image.Point, 0, max(dx, -dy)+1)
+	for {
+		points = append(points, image.Pt(x0, y0))
+		if x0 == x1 && y0 == y1 {
+			return points
+		}
+
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// Line draws a line including both ends.
+func (g *Graphics) Line(x0, y0, x1, y1 int) {
+	for _, p := range linePoints(x0, y0, x1, y1) {
+		g.Plot(p.X, p.Y)
+	}
+}
+
+// Polyline draws lines joining the points, drawing shared ends once.
+func (g *Graphics) Polyline(points ...image.Point) {
+	var all []image.Point
+	for i := 1; i < len(points); i++ {
+		all = append(all, linePoints(points[i-1].X, points[i-1].Y, points[i].X, points[i].Y)...)
+	}
+	g.plotPoints(all)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Rectangles                                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+// Rect draws the outline of a rectangle, inside its bounds.
+func (g *Graphics) Rect(r image.Rectangle) {
+	r = r.Canon()
+	if r.Empty() {
+		return
+	}
+
+	x0, y0, x1, y1 := r.Min.X, r.Min.Y, r.Max.X-1, r.Max.Y-1
+	g.plotSpan(x0, x1, y0)
+	if y1 > y0 {
+		g.plotSpan(x0, x1, y1)
+	}
+	for y := y0 + 1; y < y1; y++ {
+		g.Plot(x0, y)
+		if x1 > x0 {
+			g.Plot(x1, y)
+		}
+	}
+}
+
+// FillRect fills a rectangle.
+func (g *Graphics) FillRect(r image.Rectangle) {
+	r = r.Canon().Intersect(g.clip)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		g.plotSpan(r.Min.X, r.Max.X-1, y)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Circles                                                                    //
+////////////////////////////////////////////////////////////////////////////////
+
+// circlePoints returns the outline of a circle from the midpoint algorithm, which may contain duplicates.
+func circlePoints(cx, cy, radius int) []image.Point {
+	var points []image.Point
+
+	x, y := radius, 0
+	e := 1 - radius
+	for x >= y {
+		points = append(points,
+			image.Pt(cx+x, cy+y), image.Pt(cx+y, cy+x), image.Pt(cx-y, cy+x), image.Pt(cx-x, cy+y),
+			image.Pt(cx-x, cy-y), image.Pt(cx-y, cy-x), image.Pt(cx+y, cy-x), image.Pt(cx+x, cy-y),
+		)
+
+		y++
+		if e < 0 {
+			e += 2*y + 1
+		} else {
+			x--
+			e += 2*(y-x) + 1
+		}
+	}
+	return points
+}
+
+// Circle draws the outline of a circle.
+func (g *Graphics) Circle(cx, cy, radius int) {
+	if radius < 0 {
+		return
+	}
+	g.plotPoints(circlePoints(cx, cy, radius))
+}
+
+// circleWidths returns the half width of a filled circle at each distance from its center row, matching its outline.
+func circleWidths(radius int) []int {
+	half := make([]int, radius+1)
+	for _, p := range circlePoints(0, 0, radius) {
+		if p.Y >= 0 {
+			half[p.Y] = max(half[p.Y], p.X)
+		}
+	}
+	return half
+}
+
+// FillCircle fills a circle.
+func (g *Graphics) FillCircle(cx, cy, radius int) {
+	if radius < 0 {
+		return
+	}
+
+	half := circleWidths(radius)
+	for dy := -radius; dy <= radius; dy++ {
+		w := half[abs(dy)]
+		g.plotSpan(cx-w, cx+w, cy+dy)
+	}
+}
+
+// arcPoints returns the points of a circle's outline from a start angle clockwise to an end angle.
+func arcPoints(cx, cy, radius int, start, end float64) []image.Point {
+	points := circlePoints(cx, cy, radius)
+
+	sweep := end - start
+	if sweep >= 360 {
+		return points
+	}
+	sweep = wrapDegrees(sweep)
+
+	arc := points[:0]
+	for _, p := range points {
+		angle := math.Atan2(float64(p.Y-cy), float64(p.X-cx)) * 180 / math.Pi
+		if wrapDegrees(angle-start) <= sweep {
+			arc = append(arc, p)
+		}
+	}
+	return arc
+}
+
+// Arc draws part of the outline of a circle from a start angle clockwise to an end angle, in degrees clockwise from the positive x axis.
+func (g *Graphics) Arc(cx, cy, radius int, start, end float64) {
+	if radius < 0 {
+		return
+	}
+	g.plotPoints(arcPoints(cx, cy, radius, start, end))
+}
+
+// PointOnCircle returns the point at an angle in degrees clockwise from the positive x axis.
+func PointOnCircle(cx, cy int, radius, angle float64) image.Point {
+	rad := angle * math.Pi / 180
+	return image.Pt(cx+int(math.Round(radius*math.Cos(rad))), cy+int(math.Round(radius*math.Sin(rad))))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Utils                                                                      //
+////////////////////////////////////////////////////////////////////////////////
+
+// wrapDegrees wraps an angle to the range 0 to 360, exclusive.
+func wrapDegrees(angle float64) float64 {
+	angle = math.Mod(angle, 360)
+	if angle < 0 {
+		angle += 360
+	}
+	return angle
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
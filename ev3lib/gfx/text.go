@@ -0,0 +1,107 @@
+package gfx
+
+import (
+	"image"
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Font                                                                       //
+////////////////////////////////////////////////////////////////////////////////
+
+// Font is a fixed width bitmap font.
+type Font interface {
+	// Size returns the width and height of each character cell.
+	Size() (width, height int)
+
+	// Glyph returns the black pixels of a character relative to the top left of its cell, or nil if it has none.
+	Glyph(r rune) []image.Point
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Text                                                                       //
+////////////////////////////////////////////////////////////////////////////////
+
+// Align is where text is placed within a box, along one axis.
+type Align int
+
+const (
+	// AlignStart places text at the left or top.
+	AlignStart Align = iota
+
+	// AlignCenter centers text.
+	AlignCenter
+
+	// AlignEnd places text at the right or bottom.
+	AlignEnd
+)
+
+// offset returns how far to move something of a size to align it within a space.
+func (a Align) offset(space, size int) int {
+	switch a {
+	case AlignCenter:
+		return (space - size) / 2
+	case AlignEnd:
+		return space - size
+	}
+	return 0
+}
+
+// MeasureText returns the width and height of text in the font, where each \n starts a new line.
+func (g *Graphics) MeasureText(text string) (width, height int) {
+	if g.font == nil {
+		return 0, 0
+	}
+
+	w, h := g.font.Size()
+	lines := strings.Split(text, "\n")
+	for _, line := range lines {
+		width = max(width, len([]rune(line))*w)
+	}
+	return width, len(lines) * h
+}
+
+// DrawText draws text with its top left at a point, where each \n starts a new line.
+func (g *Graphics) DrawText(x, y int, text string) {
+	g.DrawTextAligned(x, y, text, AlignStart)
+}
+
+// DrawTextAligned draws text with its top at y, and its lines aligned on x, e.g. centered on x.
+func (g *Graphics) DrawTextAligned(x, y int, text string, align Align) {
+	if g.font == nil {
+		return
+	}
+
+	w, h := g.font.Size()
+	var points []image.Point
+	for i, line := range strings.Split(text, "\n") {
+		runes := []rune(line)
+		lx := x + align.offset(0, len(runes)*w)
+
+		for j, r := range runes {
+			for _, p := range g.font.Glyph(r) {
+				points = append(points, image.Pt(lx+j*w+p.X, y+i*h+p.Y))
+			}
+		}
+	}
+	g.plotPoints(points)
+}
+
+// DrawTextIn draws text aligned within a rectangle, clipped to it.
+func (g *Graphics) DrawTextIn(r image.Rectangle, text string, horizontal, vertical Align) {
+	_, height := g.MeasureText(text)
+
+	x := r.Min.X
+	switch horizontal {
+	case AlignCenter:
+		x += r.Dx() / 2
+	case AlignEnd:
+		x = r.Max.X
+	}
+	y := r.Min.Y + vertical.offset(r.Dy(), height)
+
+	clip := g.clip
+	g.SetClip(r.Intersect(clip))
+	g.DrawTextAligned(x, y, text, horizontal)
+	g.clip = clip
+}
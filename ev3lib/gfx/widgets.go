@@ -0,0 +1,96 @@
+package gfx
+
+import (
+	"image"
+	"math"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Bars                                                                       //
+////////////////////////////////////////////////////////////////////////////////
+
+// fraction returns how far a value is from min to max, from 0 to 1.
+func fraction(value, min, max float64) float64 {
+	if max == min {
+		return 0
+	}
+	return math.Max(0, math.Min(1, (value-min)/(max-min)))
+}
+
+// Bar draws an outlined bar filled by a value from min to max.
+// It fills from the left if the rectangle is wider than it is tall, otherwise from the bottom.
+func (g *Graphics) Bar(r image.Rectangle, value, min, max float64) {
+	r = r.Canon()
+	g.Rect(r)
+
+	inner := r.Inset(2)
+	if inner.Empty() {
+		return
+	}
+
+	f := fraction(value, min, max)
+	if r.Dx() >= r.Dy() {
+		inner.Max.X = inner.Min.X + int(math.Round(f*float64(inner.Dx())))
+	} else {
+		inner.Min.Y = inner.Max.Y - int(math.Round(f*float64(inner.Dy())))
+	}
+	g.FillRect(inner)
+}
+
+// BarGraph draws a vertical bar for each value from min to max, side by side within a rectangle with a baseline.
+func (g *Graphics) BarGraph(r image.Rectangle, values []float64, min, max float64) {
+	r = r.Canon()
+	if len(values) == 0 || r.Empty() {
+		return
+	}
+
+	// The baseline is the bottom row
+	g.plotSpan(r.Min.X, r.Max.X-1, r.Max.Y-1)
+
+	width := r.Dx() / len(values)
+	height := r.Dy() - 1
+	for i, v := range values {
+		h := int(math.Round(fraction(v, min, max) * float64(height)))
+		x := r.Min.X + i*width
+
+		// Leave a gap between bars if there is room
+		bar := image.Rect(x, r.Max.Y-1-h, x+width, r.Max.Y-1)
+		if width > 2 {
+			bar.Max.X--
+		}
+		g.FillRect(bar)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Gauge                                                                      //
+////////////////////////////////////////////////////////////////////////////////
+
+// Gauge draws a semicircular dial over a center point, with ticks at each quarter and a needle pointing at a value from min to max.
+func (g *Graphics) Gauge(cx, cy, radius int, value, min, max float64) {
+	if radius < 0 {
+		return
+	}
+
+	// Parts overlap, so they are collected and drawn once for inverting
+	points := arcPoints(cx, cy, radius, 180, 360)
+
+	for i := 0; i <= 4; i++ {
+		angle := 180 + 45*float64(i)
+		p0 := PointOnCircle(cx, cy, float64(radius), angle)
+		p1 := PointOnCircle(cx, cy, float64(radius)*0.8, angle)
+		points = append(points, linePoints(p0.X, p0.Y, p1.X, p1.Y)...)
+	}
+
+	tip := PointOnCircle(cx, cy, float64(radius)*0.9, 180+180*fraction(value, min, max))
+	points = append(points, linePoints(cx, cy, tip.X, tip.Y)...)
+
+	hub := circleWidths(2)
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -hub[abs(dy)]; dx <= hub[abs(dy)]; dx++ {
+			points = append(points, image.Pt(cx+dx, cy+dy))
+		}
+	}
+
+	g.plotPoints(points)
+}
@@ -0,0 +1,238 @@
+package ev3lib
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Heading Tracker                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+const (
+	// gyroRawLimit is the largest angle the EV3 gyro can report, some gyros freeze here instead of overflowing.
+	gyroRawLimit = 32767
+
+	// driftThreshold is the bias in degrees per second above which a gyro is treated as faulty.
+	driftThreshold = 1.0
+
+	// stationaryTime is how long the gyro must report no rotation before its bias is re-estimated.
+	stationaryTime = 500 * time.Millisecond
+
+	// biasFilter is how quickly the bias follows estimates made while stationary, from 0 to 1.
+	biasFilter = 0.1
+)
+
+// HeadingTracker tracks the heading of a gyro, removing its drift and handling the limits of its angle.
+// It can be used anywhere a GyroSensorInterface is, with angles increasing clockwise like the EV3 gyro.
+type HeadingTracker struct {
+	gyro GyroSensorInterface
+
+	bias    float64
+	heading float64
+	rate    float64
+
+	lastAngle       float64
+	lastTime        time.Time
+	stationarySince time.Time
+	stationaryAngle float64
+
+	m sync.Mutex
+}
+
+var _ GyroSensorInterface = &HeadingTracker{}
+
+// NewHeadingTracker creates a tracker with a heading of 0 and no bias, call MeasureBias while the robot is still to remove drift.
+func NewHeadingTracker(gyro GyroSensorInterface) *HeadingTracker {
+	h := &HeadingTracker{gyro: gyro}
+	h.Reset(0)
+	return h
+}
+
+// Reset sets the current heading.
+func (h *HeadingTracker) Reset(heading float64) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.heading = heading
+	h.lastAngle, h.rate = h.gyro.AngleRate()
+	h.lastTime = Now()
+	h.stationarySince = time.Time{}
+}
+
+// MeasureBias measures the drift of the gyro while the robot is still, blocking for the duration.
+// Returns the bias in degrees per second.
+func (h *HeadingTracker) MeasureBias(duration time.Duration) float64 {
+	start, _ := h.gyro.AngleRate()
+	startTime := Now()
+
+	GetClock().Sleep(duration)
+
+	end, _ := h.gyro.AngleRate()
+	elapsed := Since(startTime).Seconds()
+
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if elapsed > 0 {
+		h.bias = (end - start) / elapsed
+	}
+	h.lastAngle = end
+	h.lastTime = Now()
+
+	return h.bias
+}
+
+// Bias returns the drift being removed, in degrees per second.
+func (h *HeadingTracker) Bias() float64 {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	return h.bias
+}
+
+// IsDrifting returns whether the measured bias is large enough that the gyro has the startup drift fault,
+// where it was moved while powering on. Unplugging and reconnecting the gyro while it is still fixes it.
+func (h *HeadingTracker) IsDrifting() bool {
+	return math.Abs(h.Bias()) > driftThreshold
+}
+
+// CheckDrift measures the bias and warns on the LCD if the gyro is drifting. Returns whether it is drifting.
+func (h *HeadingTracker) CheckDrift(brick *EV3Brick, duration time.Duration) bool {
+	bias := h.MeasureBias(duration)
+	if !h.IsDrifting() {
+		return false
+	}
+
+	fmt.Printf("gyro is drifting at %.2f deg/s, reconnect it while the robot is still\n", bias)
+	if brick != nil {
+		brick.PrintScreen("Gyro drifting!", fmt.Sprintf("%.2f deg/s", bias), "Keep still and", "reconnect gyro")
+	}
+	return true
+}
+
+// Update integrates the gyro since the last update. It is called by every read, but can also be registered with AddPeriodic.
+func (h *HeadingTracker) Update() {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.update()
+}
+
+func (h *HeadingTracker) update() {
+	angle, rate := h.gyro.AngleRate()
+	now := Now()
+	dt := now.Sub(h.lastTime).Seconds()
+	h.lastTime = now
+
+	// Overflows are unwrapped by the gyro driver, but a gyro which froze at its limit needs to fall back to the rate
+	delta := angle - h.lastAngle
+	if delta == 0 && rate != 0 && h.isFrozen() {
+		delta = rate * dt
+	}
+	h.lastAngle = angle
+
+	h.heading += delta - h.bias*dt
+	h.rate = rate - h.bias
+
+	h.updateBias(angle, rate, now)
+}
+
+// isFrozen returns whether the gyro's last hardware reading is at its limit.
+// Gyros which do not provide their raw angle, such as simulated ones, never freeze.
+func (h *HeadingTracker) isFrozen() bool {
+	r, ok := h.gyro.(interface{ RawAngle() (float64, bool) })
+	if !ok {
+		return false
+	}
+
+	raw, ok := r.RawAngle()
+	return ok && math.Abs(raw) >= gyroRawLimit
+}
+
+// updateBias re-estimates the bias from the change in angle while the gyro reports no rotation.
+func (h *HeadingTracker) updateBias(angle, rate float64, now time.Time) {
+	if rate != 0 {
+		h.stationarySince = time.Time{}
+		return
+	}
+
+	if h.stationarySince.IsZero() {
+		h.stationarySince = now
+		h.stationaryAngle = angle
+		return
+	}
+
+	if elapsed := now.Sub(h.stationarySince); elapsed >= stationaryTime {
+		estimate := (angle - h.stationaryAngle) / elapsed.Seconds()
+		h.bias += biasFilter * (estimate - h.bias)
+
+		h.stationarySince = now
+		h.stationaryAngle = angle
+	}
+}
+
+// Heading returns the continuous heading in degrees, which keeps increasing past 360.
+func (h *HeadingTracker) Heading() float64 {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.update()
+	return h.heading
+}
+
+// WrappedHeading returns the heading in degrees from -180, exclusive, to 180.
+func (h *HeadingTracker) WrappedHeading() float64 {
+	return WrapAngle(h.Heading())
+}
+
+// WrapAngle wraps an angle in degrees to the range -180, exclusive, to 180.
+func WrapAngle(angle float64) float64 {
+	a := math.Mod(angle, 360)
+	if a > 180 {
+		a -= 360
+	} else if a <= -180 {
+		a += 360
+	}
+	return a
+}
+
+// Rate returns the rotational speed with the bias removed, in degrees per second.
+func (h *HeadingTracker) Rate() float64 {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.update()
+	return h.rate
+}
+
+// Angle returns the continuous heading, see Heading.
+func (h *HeadingTracker) Angle() float64 {
+	return h.Heading()
+}
+
+func (h *HeadingTracker) AngleRate() (float64, float64) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.update()
+	return h.heading, h.rate
+}
+
+// ResetAngle sets the current heading, see Reset.
+func (h *HeadingTracker) ResetAngle(angle float64) {
+	h.Reset(angle)
+}
+
+// Calibrate calibrates the gyro, then measures its bias for a second. The robot must be still.
+func (h *HeadingTracker) Calibrate() {
+	h.m.Lock()
+	heading := h.heading
+	h.m.Unlock()
+
+	h.gyro.Calibrate()
+	h.Reset(heading)
+	h.MeasureBias(time.Second)
+}
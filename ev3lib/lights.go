@@ -0,0 +1,136 @@
+package ev3lib
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Light Colors                                                               //
+////////////////////////////////////////////////////////////////////////////////
+
+// LEDBrightness maps the color to the brightness of the red and green lights, from 0 to 1.
+// The hue is matched by mixing, where the green light is weaker so an equal mix looks amber,
+// and less saturated colors are mixed towards amber. Blues and purples are shown as green and red.
+func (c EV3Color) LEDBrightness() (red, green float64) {
+	h, s, v := RGBToHSV(c.r, c.g, c.b)
+
+	switch {
+	case h <= 30:
+		red, green = 1, h/30
+	case h <= 60:
+		red, green = 1-0.9*(h-30)/30, 1
+	case h <= 120:
+		red, green = 0.1*(120-h)/60, 1
+	case h <= 240:
+		red, green = 0, 1
+	default:
+		red, green = 1, 0
+	}
+
+	red = v * (s*red + 1 - s)
+	green = v * (s*green + 1 - s)
+	return red, green
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Light Patterns                                                             //
+////////////////////////////////////////////////////////////////////////////////
+
+// LightStep shows a color for a duration.
+type LightStep struct {
+	Color    EV3Color
+	Duration time.Duration
+}
+
+// LightPattern is a sequence of steps, repeated until the lights are set again.
+type LightPattern []LightStep
+
+// BlinkPattern turns a color on and off.
+func BlinkPattern(color EV3Color, on, off time.Duration) LightPattern {
+	return LightPattern{{Color: color, Duration: on}, {Color: OffLight, Duration: off}}
+}
+
+// PulsePattern fades a color in and out over a period.
+func PulsePattern(color EV3Color, period time.Duration) LightPattern {
+	const steps = 20
+
+	pattern := make(LightPattern, steps)
+	for i := range pattern {
+		brightness := (1 - math.Cos(2*math.Pi*float64(i)/steps)) / 2
+		pattern[i] = LightStep{Color: color.WithBrightness(brightness), Duration: period / steps}
+	}
+	return pattern
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Light Pattern Player                                                       //
+////////////////////////////////////////////////////////////////////////////////
+
+// LightPatternPlayer plays light patterns in a goroutine, for implementing PlayLightPattern.
+type LightPatternPlayer struct {
+	m sync.Mutex
+
+	set  func(color EV3Color)
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewLightPatternPlayer creates a player which sets both lights with set.
+func NewLightPatternPlayer(set func(color EV3Color)) *LightPatternPlayer {
+	return &LightPatternPlayer{set: set}
+}
+
+// Play stops the current pattern and starts repeating a new one.
+func (p *LightPatternPlayer) Play(pattern LightPattern) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	p.stopLocked()
+	if len(pattern) == 0 {
+		return
+	}
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	p.stop, p.done = stop, done
+
+	go func() {
+		defer close(done)
+
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		<-timer.C
+
+		for {
+			for _, step := range pattern {
+				p.set(step.Color)
+
+				timer.Reset(step.Duration)
+				select {
+				case <-stop:
+					return
+				case <-timer.C:
+				}
+			}
+		}
+	}()
+}
+
+func (p *LightPatternPlayer) stopLocked() {
+	if p.stop == nil {
+		return
+	}
+
+	close(p.stop)
+	<-p.done
+	p.stop, p.done = nil, nil
+}
+
+// Stop stops the current pattern, leaving the lights on its last color.
+func (p *LightPatternPlayer) Stop() {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	p.stopLocked()
+}
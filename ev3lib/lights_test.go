@@ -0,0 +1,117 @@
+package ev3lib_test
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+func TestLEDBrightness(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		color      ev3lib.EV3Color
+		red, green float64
+	}{
+		{"off", ev3lib.OffLight, 0, 0},
+		{"red", ev3lib.RedLight, 1, 0},
+		{"orange", ev3lib.OrangeLight, 1, 0.5},
+		{"amber", ev3lib.AmberLight, 1, 1},
+		{"yellow", ev3lib.YellowLight, 0.1, 1},
+		{"green", ev3lib.GreenLight, 0, 1},
+		// Blues and purples have no light of their own
+		{"blue", ev3lib.NewColor(0, 0, 1), 0, 1},
+		{"magenta", ev3lib.NewColor(1, 0, 1), 1, 0},
+		// Unsaturated colors are mixed towards amber
+		{"white", ev3lib.NewColor(1, 1, 1), 1, 1},
+		{"dim red", ev3lib.RedLight.WithBrightness(0.5), 0.5, 0},
+		{"pale green", ev3lib.NewColor(0.5, 1, 0.5), 0.5, 1},
+	} {
+		red, green := test.color.LEDBrightness()
+		if math.Abs(red-test.red) > 1e-9 || math.Abs(green-test.green) > 1e-9 {
+			t.Errorf("%v: LEDBrightness = %v, %v, want %v, %v", test.name, red, green, test.red, test.green)
+		}
+	}
+}
+
+// lightRecorder records the colors set by a LightPatternPlayer.
+type lightRecorder struct {
+	m      sync.Mutex
+	colors []ev3lib.EV3Color
+}
+
+func (r *lightRecorder) set(color ev3lib.EV3Color) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.colors = append(r.colors, color)
+}
+
+func (r *lightRecorder) count() int {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	return len(r.colors)
+}
+
+// waitFor waits until the recorder has at least n colors, failing the test after a second.
+func (r *lightRecorder) waitFor(t *testing.T, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for r.count() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%v colors set, want at least %v", r.count(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLightPatternPlayer(t *testing.T) {
+	r := &lightRecorder{}
+	p := ev3lib.NewLightPatternPlayer(r.set)
+
+	// Patterns repeat until stopped
+	p.Play(ev3lib.BlinkPattern(ev3lib.RedLight, time.Millisecond, time.Millisecond))
+	r.waitFor(t, 4)
+	p.Stop()
+
+	stopped := r.count()
+	for i, color := range r.colors {
+		want := ev3lib.RedLight
+		if i%2 == 1 {
+			want = ev3lib.OffLight
+		}
+		if color != want {
+			t.Fatalf("color %v = %v, want %v", i, color, want)
+		}
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if got := r.count(); got != stopped {
+		t.Errorf("%v colors set after stopping, want none", got-stopped)
+	}
+
+	// Stopping twice is fine, and a stopped player plays again from the first step
+	p.Stop()
+	p.Play(ev3lib.LightPattern{{Color: ev3lib.GreenLight, Duration: time.Hour}})
+	r.waitFor(t, stopped+1)
+	if got := r.colors[stopped]; got != ev3lib.GreenLight {
+		t.Errorf("restarted color = %v, want %v", got, ev3lib.GreenLight)
+	}
+
+	// Playing replaces the current pattern, and an empty pattern just stops it
+	p.Play(ev3lib.LightPattern{{Color: ev3lib.AmberLight, Duration: time.Hour}})
+	r.waitFor(t, stopped+2)
+	p.Play(nil)
+
+	time.Sleep(10 * time.Millisecond)
+	if got := r.count(); got != stopped+2 {
+		t.Errorf("%v colors set, want %v", got, stopped+2)
+	}
+	if got := r.colors[stopped+1]; got != ev3lib.AmberLight {
+		t.Errorf("replacing color = %v, want %v", got, ev3lib.AmberLight)
+	}
+}
@@ -0,0 +1,156 @@
+package ev3lib
+
+import (
+	"math"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Line Follow Config                                                         //
+////////////////////////////////////////////////////////////////////////////////
+
+// LineEdge is the side of the line followed by a single sensor.
+type LineEdge int
+
+const (
+	// LeftEdge follows the left edge of a dark line, with white to the left of the sensor.
+	LeftEdge LineEdge = iota
+
+	// RightEdge follows the right edge of a dark line, with white to the right of the sensor.
+	RightEdge
+)
+
+// LineFollowConfig sets how a line is followed and when to stop.
+// The PID steers with positive outputs turning clockwise, on an error of about -1 to 1 for every follower.
+type LineFollowConfig struct {
+	Speed float64
+	PID   *PIDController
+
+	// Target is the reflection kept under a single sensor following an edge, usually halfway between black and white.
+	Target float64
+
+	// Black is the reflection below which a sensor is over the line, used to detect junctions.
+	Black float64
+
+	// Distance stops after driving a distance, 0 follows forever.
+	Distance float64
+
+	// StopAtJunction stops when the outer sensors both see the line. Only used by two and three sensor followers.
+	StopAtJunction bool
+
+	// Timeout stops after a duration, 0 follows forever.
+	Timeout time.Duration
+
+	// Until stops when it returns true, if not nil.
+	Until func() bool
+}
+
+// DefaultLineFollowConfig returns a config with a target of 0.5, black below 0.2 and no stop conditions.
+func DefaultLineFollowConfig(speed float64) LineFollowConfig {
+	return LineFollowConfig{
+		Speed:  speed,
+		PID:    NewPIDController(1, 0, 0.05),
+		Target: 0.5,
+		Black:  0.2,
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Line Follow Command                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+type lineFollowCommand struct {
+	DefaultCommand
+
+	config LineFollowConfig
+
+	// lineError returns how far the line is to the right of where it should be
+	lineError func() float64
+	junction  func() bool
+
+	startDistance, travelled float64
+	atJunction               bool
+
+	d *DifferentialDrive
+}
+
+func (c *lineFollowCommand) Init() {
+	c.startDistance = c.d.Distance()
+	c.travelled = 0
+	c.atJunction = false
+	c.config.PID.Reset()
+}
+
+func (c *lineFollowCommand) Run() {
+	c.travelled = math.Abs(c.d.Distance() - c.startDistance)
+
+	if c.config.StopAtJunction && c.junction != nil && c.junction() {
+		c.atJunction = true
+		return
+	}
+
+	remaining := math.Inf(1)
+	if c.config.Distance > 0 {
+		remaining = c.config.Distance - c.travelled
+	}
+	speed := c.d.rampedSpeed(c.config.Speed, c.travelled, remaining)
+
+	turn := -c.config.PID.Get(c.lineError(), 0)
+	c.d.SetArcade(speed, turn)
+}
+
+func (c *lineFollowCommand) End(bool) {
+	c.d.Stop()
+}
+
+func (c *lineFollowCommand) IsDone() bool {
+	return c.atJunction || (c.config.Distance > 0 && c.travelled >= c.config.Distance)
+}
+
+func (d *DifferentialDrive) newLineFollowCommand(config LineFollowConfig, lineError func() float64, junction func() bool) *Command {
+	cmd := NewCommand(&lineFollowCommand{config: config, lineError: lineError, junction: junction, d: d}).Requires(d.Subsystem)
+
+	if config.Until != nil {
+		cmd = cmd.Until(config.Until)
+	}
+	if config.Timeout > 0 {
+		cmd = cmd.WithTimeout(config.Timeout)
+	}
+	return cmd
+}
+
+// FollowEdge follows one edge of a line with a single sensor, keeping its reflection at the target.
+func (d *DifferentialDrive) FollowEdge(sensor ColorSensorInterface, edge LineEdge, config LineFollowConfig) *Command {
+	return d.newLineFollowCommand(config, func() float64 {
+		// Too much white means the sensor has drifted off the line, away from the edge
+		e := sensor.Reflection() - config.Target
+		if edge == RightEdge {
+			e = -e
+		}
+		return e
+	}, nil)
+}
+
+// FollowLineTwoSensors keeps a line centred between two sensors by balancing their reflections.
+func (d *DifferentialDrive) FollowLineTwoSensors(left, right ColorSensorInterface, config LineFollowConfig) *Command {
+	return d.newLineFollowCommand(config, func() float64 {
+		return left.Reflection() - right.Reflection()
+	}, func() bool {
+		return left.Reflection() < config.Black && right.Reflection() < config.Black
+	})
+}
+
+// FollowLineThreeSensors keeps a line under the centre sensor, estimating its position from how dark each sensor is.
+func (d *DifferentialDrive) FollowLineThreeSensors(left, centre, right ColorSensorInterface, config LineFollowConfig) *Command {
+	return d.newLineFollowCommand(config, func() float64 {
+		dl, dc, dr := 1-left.Reflection(), 1-centre.Reflection(), 1-right.Reflection()
+
+		sum := dl + dc + dr
+		if sum <= 0 {
+			return 0
+		}
+		return (dr - dl) / sum
+	}, func() bool {
+		return left.Reflection() < config.Black && right.Reflection() < config.Black
+	})
+}
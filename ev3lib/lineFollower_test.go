@@ -0,0 +1,73 @@
+package ev3lib_test
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/Alanlu217/ev3lib/ev3lib/testUtils"
+)
+
+// lineMat returns a 2000 by 1000mm white mat, at 2mm a pixel, with a 20mm black line along y = 500 which fades to white over 15mm either side.
+func lineMat() image.Image {
+	img := image.NewGray(image.Rect(0, 0, 1000, 500))
+	for py := range 500 {
+		y := 1000 - (float64(py)+0.5)*2
+		edge := math.Abs(y-500) - 10
+		shade := uint8(255 * ev3lib.Clamp(edge/15, 0, 1))
+
+		for px := range 1000 {
+			img.SetGray(px, py, color.Gray{Y: shade})
+		}
+	}
+	return img
+}
+
+func TestLineFollow(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		cmd  func(w *testUtils.SimWorld, d *ev3lib.DifferentialDrive, config ev3lib.LineFollowConfig) *ev3lib.Command
+
+		// startY is beside the edge being followed, lineY is where the centre of the robot settles
+		startY, lineY float64
+	}{
+		{"two sensors", func(w *testUtils.SimWorld, d *ev3lib.DifferentialDrive, config ev3lib.LineFollowConfig) *ev3lib.Command {
+			return d.FollowLineTwoSensors(w.NewColorSensor(60, 20), w.NewColorSensor(60, -20), config)
+		}, 480, 500},
+		{"three sensors", func(w *testUtils.SimWorld, d *ev3lib.DifferentialDrive, config ev3lib.LineFollowConfig) *ev3lib.Command {
+			return d.FollowLineThreeSensors(w.NewColorSensor(60, 20), w.NewColorSensor(60, 0), w.NewColorSensor(60, -20), config)
+		}, 480, 500},
+		// White is to the left of the sensor on the left edge, so the sensor sits above the line halfway through the fade
+		{"left edge", func(w *testUtils.SimWorld, d *ev3lib.DifferentialDrive, config ev3lib.LineFollowConfig) *ev3lib.Command {
+			return d.FollowEdge(w.NewColorSensor(60, 0), ev3lib.LeftEdge, config)
+		}, 530, 517.5},
+		{"right edge", func(w *testUtils.SimWorld, d *ev3lib.DifferentialDrive, config ev3lib.LineFollowConfig) *ev3lib.Command {
+			return d.FollowEdge(w.NewColorSensor(60, 0), ev3lib.RightEdge, config)
+		}, 470, 482.5},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c, w, d := newSimDrive(t, false)
+			w.SetMat(lineMat(), 2000, 1000)
+
+			// Start beside the line, pointing slightly away from it
+			w.SetPose(100, test.startY, math.Copysign(5, test.startY-test.lineY))
+
+			config := ev3lib.DefaultLineFollowConfig(0.4)
+			config.PID = ev3lib.NewPIDController(0.5, 0, 0.02)
+			config.Distance = 1200
+
+			runSim(t, c, w, test.cmd(w, d, config), 10*time.Second)
+
+			x, y, heading := w.Pose()
+			if x < 1200 {
+				t.Errorf("x = %v, want past 1200", x)
+			}
+			if math.Abs(y-test.lineY) > 10 || math.Abs(heading) > 5 {
+				t.Errorf("finished at y = %v heading %v, want y = %v heading 0", y, heading, test.lineY)
+			}
+		})
+	}
+}
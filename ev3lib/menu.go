@@ -2,7 +2,6 @@ package ev3lib
 
 import (
 	"fmt"
-	"log"
 	"time"
 )
 
@@ -67,8 +66,25 @@ func NewMainMenu(i MainMenuInterface, m *Menu) *MainMenu {
 	return &MainMenu{i, m, 0, 0}
 }
 
+// setStatus shows the menu status if the menu interface supports it.
+func (m *MainMenu) setStatus(status MenuStatus) {
+	if s, ok := m.i.(MenuStatusInterface); ok {
+		s.SetStatus(status)
+	}
+}
+
 func (m *MainMenu) Start() {
-	t := time.NewTicker(time.Millisecond * 50)
+	// Show the fault before a panic, such as a device fault, ends the program
+	defer func() {
+		if r := recover(); r != nil {
+			m.setStatus(MenuFault)
+			panic(r)
+		}
+	}()
+
+	m.setStatus(MenuIdle)
+
+	t := newIntervalTimer(time.Millisecond * 50)
 
 main:
 	for {
@@ -77,6 +93,8 @@ main:
 			break main
 		}
 
+		runPeriodics()
+
 		if m.i.NextCommand() {
 			m.commandIdx += 1
 		}
@@ -112,45 +130,21 @@ main:
 		if m.i.RunSelected() {
 			m.i.Display(m.m, m.commandIdx, m.pageIdx, true)
 
-			intervalTime := 20 * time.Millisecond
 			c := m.m.Pages[m.pageIdx].Commands[m.commandIdx]
 
-			t := time.NewTicker(intervalTime)
-
-			start := time.Now()
-
-			c.Init()
-
-			for !c.IsDone() {
-				if m.i.CancelRun() && time.Since(start) > 100*time.Millisecond {
-					c.End(true)
-					t.Stop()
-
-					fmt.Printf("%v took %v\n", c.Name, time.Since(start))
-
-					continue main
-				}
-
-				start := time.Now()
-
-				c.Run()
-
-				delta := time.Since(start)
-
-				if delta > intervalTime {
-					log.Printf("Loop time overrun, took: %v\n", delta)
-				}
+			start := Now()
 
-				<-t.C
-			}
-			c.End(false)
-			fmt.Printf("%v took %v\n", c.Name, time.Since(start))
+			m.setStatus(MenuRunning)
+			runLoop(c.Command, 20*time.Millisecond, func() bool {
+				return m.i.CancelRun() && Since(start) > 100*time.Millisecond
+			})
+			m.setStatus(MenuIdle)
 
-			t.Stop()
+			fmt.Printf("%v took %v\n", c.Name, Since(start))
 		}
 
 		m.i.Display(m.m, m.commandIdx, m.pageIdx, false)
 
-		<-t.C
+		t.wait()
 	}
 }
@@ -0,0 +1,70 @@
+package ev3lib_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+// scriptedMenu runs the selected command once, then exits. It does not show a status.
+type scriptedMenu struct {
+	frames int
+}
+
+func (m *scriptedMenu) Exit() bool              { return m.frames >= 2 }
+func (m *scriptedMenu) RunSelected() bool       { return m.frames == 0 }
+func (m *scriptedMenu) CancelRun() bool         { return false }
+func (m *scriptedMenu) NextCommand() bool       { return false }
+func (m *scriptedMenu) PreviousCommand() bool   { return false }
+func (m *scriptedMenu) SetCommand() (bool, int) { return false, 0 }
+func (m *scriptedMenu) NextPage() bool          { return false }
+func (m *scriptedMenu) PreviousPage() bool      { return false }
+func (m *scriptedMenu) SetPage() (bool, int)    { return false, 0 }
+
+func (m *scriptedMenu) Display(menu *ev3lib.Menu, command, page int, running bool) {
+	if !running {
+		m.frames++
+	}
+}
+
+// statusMenu is a scriptedMenu which records the statuses it is shown.
+type statusMenu struct {
+	scriptedMenu
+
+	statuses []ev3lib.MenuStatus
+}
+
+func (m *statusMenu) SetStatus(status ev3lib.MenuStatus) {
+	m.statuses = append(m.statuses, status)
+}
+
+func newTestMenu(runs *int) *ev3lib.Menu {
+	menu := ev3lib.NewCommandMenu()
+	menu.AddPage("Test").AddCommand("Count", ev3lib.NewFuncCommand(func() { *runs++ })).Add()
+	return menu
+}
+
+func TestMainMenuWithoutStatus(t *testing.T) {
+	useManualClock(t)
+
+	runs := 0
+	ev3lib.NewMainMenu(&scriptedMenu{}, newTestMenu(&runs)).Start()
+
+	if runs != 1 {
+		t.Errorf("command ran %d times, want 1", runs)
+	}
+}
+
+func TestMainMenuStatus(t *testing.T) {
+	useManualClock(t)
+
+	runs := 0
+	m := &statusMenu{}
+	ev3lib.NewMainMenu(m, newTestMenu(&runs)).Start()
+
+	want := []ev3lib.MenuStatus{ev3lib.MenuIdle, ev3lib.MenuRunning, ev3lib.MenuIdle}
+	if !slices.Equal(m.statuses, want) {
+		t.Errorf("statuses = %v, want %v", m.statuses, want)
+	}
+}
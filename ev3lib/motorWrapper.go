@@ -1,6 +1,9 @@
 package ev3lib
 
-import "math"
+import (
+	"math"
+	"time"
+)
 
 ////////////////////////////////////////////////////////////////////////////////
 // MotorInterface Base                                                                 //
@@ -43,78 +46,206 @@ func (m *Motor) SetCommand(power float64) *Command {
 
 ////////////////////////////////////////////////////////////////////////////////
 
-type runToRelPosCommand struct {
+// PositionSettings configures when position commands finish and how hard they may drive the motor.
+type PositionSettings struct {
+	// Tolerance is the largest position error at which the motor counts as on target.
+	Tolerance float64
+
+	// SettleTime is how long the motor must stay within tolerance before the command finishes.
+	SettleTime time.Duration
+
+	// MaxOutput limits the power sent to the motor, from 0 to 1. 0 or less means no limit.
+	MaxOutput float64
+
+	// MinOutput is the smallest power sent while outside tolerance, used to overcome static friction.
+	MinOutput float64
+}
+
+// DefaultPositionSettings returns settings with a tolerance, no settle time and no output limits.
+func DefaultPositionSettings(tolerance float64) PositionSettings {
+	return PositionSettings{Tolerance: tolerance, SettleTime: 0, MaxOutput: 1, MinOutput: 0}
+}
+
+// maxOutput returns the output limit, treating an unset MaxOutput as no limit so zero value settings still move the motor.
+func (s PositionSettings) maxOutput() float64 {
+	if s.MaxOutput <= 0 {
+		return 1
+	}
+	return s.MaxOutput
+}
+
+type runToPosCommand struct {
 	DefaultCommand
 
-	pos float64
+	pos      float64
+	relative bool
 
 	targetPos float64
 
-	done      bool
-	tolerance float64
+	settings PositionSettings
 
-	pid PIDController
+	inTolerance bool
+	toleranceAt time.Time
+	done        bool
+
+	pid *PIDController
 	m   MotorInterface
 }
 
-func (r *runToRelPosCommand) Init() {
-	r.targetPos = r.pos + r.m.Position()
+func (r *runToPosCommand) Init() {
+	r.targetPos = r.pos
+	if r.relative {
+		r.targetPos += r.m.Position()
+	}
+
+	r.inTolerance = false
+	r.done = false
+	r.pid.Reset()
 }
 
-func (r *runToRelPosCommand) Run() {
-	pow := r.pid.Get(r.m.Position(), r.targetPos)
+func (r *runToPosCommand) Run() {
+	current := r.m.Position()
+	e := r.targetPos - current
+
+	if math.Abs(e) <= r.settings.Tolerance {
+		if !r.inTolerance {
+			r.inTolerance = true
+			r.toleranceAt = Now()
+		}
+
+		if Since(r.toleranceAt) >= r.settings.SettleTime {
+			r.done = true
+		}
+	} else {
+		r.inTolerance = false
+	}
+
+	pow := Clamp(r.pid.Get(current, r.targetPos), -r.settings.maxOutput(), r.settings.maxOutput())
 
-	if math.Abs(pow) < r.tolerance {
-		r.done = true
+	if !r.inTolerance && math.Abs(pow) < r.settings.MinOutput {
+		pow = math.Copysign(r.settings.MinOutput, e)
 	}
 
 	r.m.Set(pow)
 }
 
-func (r *runToRelPosCommand) End(interrupted bool) {
+func (r *runToPosCommand) End(interrupted bool) {
 	r.m.Stop()
 }
 
-func (r *runToRelPosCommand) IsDone() bool {
+func (r *runToPosCommand) IsDone() bool {
 	return r.done
 }
 
+// RunToRelPos moves the motor by `pos` from its position when the command starts, finishing within `tolerance`.
+// The command uses its own copy of `pid`, see RunToRelPosWithSettings for settling and output limits.
 func (m *Motor) RunToRelPos(pos float64, tolerance float64, pid PIDController) *Command {
-	return NewCommand(&runToRelPosCommand{pos: pos, pid: pid, m: m.MotorInterface})
+	return m.RunToRelPosWithSettings(pos, &pid, DefaultPositionSettings(tolerance))
+}
+
+// RunToAbsPos moves the motor to `pos`, finishing within `tolerance`.
+// The command uses its own copy of `pid`, see RunToAbsPosWithSettings for settling and output limits.
+func (m *Motor) RunToAbsPos(pos float64, tolerance float64, pid PIDController) *Command {
+	return m.RunToAbsPosWithSettings(pos, &pid, DefaultPositionSettings(tolerance))
+}
+
+// RunToRelPosWithSettings moves the motor by `pos` from its position when the command starts.
+// The PID controller is reset every time the command starts.
+func (m *Motor) RunToRelPosWithSettings(pos float64, pid *PIDController, settings PositionSettings) *Command {
+	return NewCommand(&runToPosCommand{pos: pos, relative: true, settings: settings, pid: pid, m: m.MotorInterface})
+}
+
+// RunToAbsPosWithSettings moves the motor to `pos`.
+// The PID controller is reset every time the command starts.
+func (m *Motor) RunToAbsPosWithSettings(pos float64, pid *PIDController, settings PositionSettings) *Command {
+	return NewCommand(&runToPosCommand{pos: pos, relative: false, settings: settings, pid: pid, m: m.MotorInterface})
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 
-type runToAbsPosCommand struct {
+type profiledMoveCommand struct {
 	DefaultCommand
 
-	pos float64
+	pos      float64
+	relative bool
 
-	done      bool
-	tolerance float64
+	startPos, targetPos float64
+	startTime           time.Time
+	plan                *ProfilePlan
 
-	pid PIDController
-	m   MotorInterface
+	settings PositionSettings
+
+	inTolerance bool
+	toleranceAt time.Time
+	done        bool
+
+	profile *MotionProfile
+	ff      Feedforward
+	pid     *PIDController
+	m       MotorInterface
+}
+
+func (r *profiledMoveCommand) Init() {
+	r.startPos = r.m.Position()
+	r.targetPos = r.pos
+	if r.relative {
+		r.targetPos += r.startPos
+	}
+
+	r.plan = r.profile.Plan(r.targetPos - r.startPos)
+	r.startTime = Now()
+
+	r.inTolerance = false
+	r.done = false
+	r.pid.Reset()
 }
 
-func (r *runToAbsPosCommand) Run() {
-	pow := r.pid.Get(r.m.Position(), r.pos)
+func (r *profiledMoveCommand) Run() {
+	t := Since(r.startTime)
+	setpoint := r.plan.Sample(t)
+
+	current := r.m.Position()
+
+	if t >= r.plan.Duration() && math.Abs(r.targetPos-current) <= r.settings.Tolerance {
+		if !r.inTolerance {
+			r.inTolerance = true
+			r.toleranceAt = Now()
+		}
+
+		if Since(r.toleranceAt) >= r.settings.SettleTime {
+			r.done = true
+		}
+	} else {
+		r.inTolerance = false
+	}
+
+	pow := r.ff.Calculate(setpoint.Velocity, setpoint.Acceleration) + r.pid.Get(current, r.startPos+setpoint.Position)
+	pow = Clamp(pow, -r.settings.maxOutput(), r.settings.maxOutput())
 
-	if math.Abs(pow) < r.tolerance {
-		r.done = true
+	// Once the profile is finished, push through static friction to reach the target
+	e := r.targetPos - current
+	if t >= r.plan.Duration() && math.Abs(e) > r.settings.Tolerance && math.Abs(pow) < r.settings.MinOutput {
+		pow = math.Copysign(r.settings.MinOutput, e)
 	}
 
 	r.m.Set(pow)
 }
 
-func (r *runToAbsPosCommand) End(interrupted bool) {
+func (r *profiledMoveCommand) End(interrupted bool) {
 	r.m.Stop()
 }
 
-func (r *runToAbsPosCommand) IsDone() bool {
+func (r *profiledMoveCommand) IsDone() bool {
 	return r.done
 }
 
-func (m *Motor) RunToAbsPos(pos float64, tolerance float64, pid PIDController) *Command {
-	return NewCommand(&runToAbsPosCommand{pos: pos, pid: pid, m: m.MotorInterface})
+// ProfiledMoveTo moves the motor to `pos`, following a motion profile using feedforward plus PID.
+// The command finishes once the profile is complete and the motor has settled within tolerance.
+func (m *Motor) ProfiledMoveTo(pos float64, profile *MotionProfile, ff Feedforward, pid *PIDController, settings PositionSettings) *Command {
+	return NewCommand(&profiledMoveCommand{pos: pos, relative: false, settings: settings, profile: profile, ff: ff, pid: pid, m: m.MotorInterface})
+}
+
+// ProfiledMoveBy moves the motor by `pos` from its position when the command starts, see ProfiledMoveTo.
+func (m *Motor) ProfiledMoveBy(pos float64, profile *MotionProfile, ff Feedforward, pid *PIDController, settings PositionSettings) *Command {
+	return NewCommand(&profiledMoveCommand{pos: pos, relative: true, settings: settings, profile: profile, ff: ff, pid: pid, m: m.MotorInterface})
 }
@@ -0,0 +1,91 @@
+package ev3lib_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+	"github.com/Alanlu217/ev3lib/ev3lib/testUtils"
+)
+
+func useManualClock(t *testing.T) *testUtils.ManualClock {
+	prev := ev3lib.GetClock()
+	t.Cleanup(func() { ev3lib.SetClock(prev) })
+
+	return testUtils.UseManualClock()
+}
+
+// runFor steps a command every 10ms until it finishes, failing if it takes longer than `limit`.
+func runFor(t *testing.T, c *testUtils.ManualClock, cmd ev3lib.CommandInterface, limit time.Duration) {
+	t.Helper()
+
+	cmd.Init()
+	for start := c.Elapsed(); !c.StepCommand(cmd, 10*time.Millisecond); {
+		if c.Elapsed()-start > limit {
+			cmd.End(true)
+			t.Fatalf("command did not finish within %v", limit)
+		}
+	}
+}
+
+func TestRunToPos(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		target float64
+		cmd    func(m *ev3lib.Motor) *ev3lib.Command
+	}{
+		{"relative", 360, func(m *ev3lib.Motor) *ev3lib.Command {
+			return m.RunToRelPos(360, 5, *ev3lib.NewPIDController(0.01, 0, 0))
+		}},
+		{"absolute", -180, func(m *ev3lib.Motor) *ev3lib.Command {
+			return m.RunToAbsPos(-180, 5, *ev3lib.NewPIDController(0.01, 0, 0))
+		}},
+		{"zero value settings", 360, func(m *ev3lib.Motor) *ev3lib.Command {
+			return m.RunToRelPosWithSettings(360, ev3lib.NewPIDController(0.01, 0, 0), ev3lib.PositionSettings{Tolerance: 5})
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c := useManualClock(t)
+			m := testUtils.NewSimLargeMotor("A")
+
+			runFor(t, c, test.cmd(m), 5*time.Second)
+
+			if e := math.Abs(m.Position() - test.target); e > 5 {
+				t.Errorf("position = %v, want %v within 5", m.Position(), test.target)
+			}
+		})
+	}
+}
+
+func TestProfiledMove(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		target   float64
+		settings ev3lib.PositionSettings
+		to       bool
+	}{
+		{"to", 720, ev3lib.DefaultPositionSettings(5), true},
+		{"by", -360, ev3lib.DefaultPositionSettings(5), false},
+		{"zero value settings", 360, ev3lib.PositionSettings{Tolerance: 5}, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c := useManualClock(t)
+			m := testUtils.NewSimLargeMotor("A")
+
+			profile := ev3lib.NewTrapezoidProfile(500, 1000)
+			ff := ev3lib.NewFeedforward(0, 1.0/1050, 0)
+			pid := ev3lib.NewPIDController(0.01, 0, 0)
+
+			cmd := m.ProfiledMoveBy(test.target, profile, ff, pid, test.settings)
+			if test.to {
+				cmd = m.ProfiledMoveTo(test.target, profile, ff, pid, test.settings)
+			}
+			runFor(t, c, cmd, 5*time.Second)
+
+			if e := math.Abs(m.Position() - test.target); e > 5 {
+				t.Errorf("position = %v, want %v within 5", m.Position(), test.target)
+			}
+		})
+	}
+}
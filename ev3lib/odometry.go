@@ -0,0 +1,161 @@
+package ev3lib
+
+import (
+	"math"
+	"sync"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Pose2d                                                                     //
+////////////////////////////////////////////////////////////////////////////////
+
+// Pose2d is a position and heading on the field.
+// Headings are in degrees counterclockwise from the x axis, the opposite direction to the EV3 gyro.
+type Pose2d struct {
+	X, Y, Heading float64
+}
+
+func NewPose2d(x, y, heading float64) Pose2d {
+	return Pose2d{X: x, Y: y, Heading: heading}
+}
+
+// DistanceTo returns the straight line distance to another pose.
+func (p Pose2d) DistanceTo(o Pose2d) float64 {
+	return math.Hypot(o.X-p.X, o.Y-p.Y)
+}
+
+// HeadingRadians returns the heading in radians.
+func (p Pose2d) HeadingRadians() float64 {
+	return p.Heading * math.Pi / 180
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Odometry                                                                   //
+////////////////////////////////////////////////////////////////////////////////
+
+// Odometry tracks the pose of a differential drive robot from its wheel positions and gyro.
+// Call Update regularly, e.g. by registering it with AddPeriodic.
+type Odometry struct {
+	left, right MotorInterface
+	gyro        GyroSensorInterface
+
+	wheelDiameter, trackWidth float64
+
+	slip       float64
+	gyroWeight float64
+
+	pose                          Pose2d
+	lastLeft, lastRight, lastGyro float64
+
+	m sync.Mutex
+}
+
+// NewOdometry creates odometry starting at the origin, with motor positions in degrees.
+// If gyro is nil the heading is tracked from the wheels alone.
+func NewOdometry(left, right MotorInterface, wheelDiameter, trackWidth float64, gyro GyroSensorInterface) *Odometry {
+	o := &Odometry{left: left, right: right, gyro: gyro, wheelDiameter: wheelDiameter, trackWidth: trackWidth, slip: 1, gyroWeight: 1}
+	o.ResetPose(Pose2d{})
+	return o
+}
+
+// NewOdometry creates odometry using the drivetrain's motors, gyro and dimensions.
+func (d *DifferentialDrive) NewOdometry() *Odometry {
+	return NewOdometry(d.left, d.right, d.wheelDiameter, d.trackWidth, d.gyro)
+}
+
+// SetSlipCompensation scales the distance measured by the wheels, e.g. 0.97 if the wheels slip 3% of the time.
+func (o *Odometry) SetSlipCompensation(factor float64) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.slip = factor
+}
+
+// SetGyroWeight sets how much the gyro is trusted over the wheels for heading changes, from 0 to 1. Defaults to 1.
+func (o *Odometry) SetGyroWeight(weight float64) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.gyroWeight = Clamp(weight, 0, 1)
+}
+
+// Update integrates the wheel and gyro movement since the last update into the pose.
+func (o *Odometry) Update() {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	l, r := o.left.Position(), o.right.Position()
+
+	dl := (l - o.lastLeft) / 360 * math.Pi * o.wheelDiameter * o.slip
+	dr := (r - o.lastRight) / 360 * math.Pi * o.wheelDiameter * o.slip
+	o.lastLeft, o.lastRight = l, r
+
+	distance := (dl + dr) / 2
+	dHeading := (dr - dl) / o.trackWidth * 180 / math.Pi
+
+	if o.gyro != nil {
+		g := o.gyro.Angle()
+		// Gyro angles increase clockwise
+		dHeading = o.gyroWeight*(o.lastGyro-g) + (1-o.gyroWeight)*dHeading
+		o.lastGyro = g
+	}
+
+	mid := (o.pose.Heading + dHeading/2) * math.Pi / 180
+	o.pose.X += distance * math.Cos(mid)
+	o.pose.Y += distance * math.Sin(mid)
+	o.pose.Heading += dHeading
+}
+
+// Pose returns the current estimated pose.
+func (o *Odometry) Pose() Pose2d {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return o.pose
+}
+
+// ResetPose sets the current pose, e.g. at the start of a run.
+func (o *Odometry) ResetPose(pose Pose2d) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.pose = pose
+	o.lastLeft, o.lastRight = o.left.Position(), o.right.Position()
+	if o.gyro != nil {
+		o.lastGyro = o.gyro.Angle()
+	}
+}
+
+// RelocalizeX corrects the x position from a known field feature, such as a wall the robot is touching.
+func (o *Odometry) RelocalizeX(x float64) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.pose.X = x
+}
+
+// RelocalizeY corrects the y position from a known field feature.
+func (o *Odometry) RelocalizeY(y float64) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.pose.Y = y
+}
+
+// RelocalizeHeading corrects the heading from a known field feature, such as after squaring up on a wall or line.
+func (o *Odometry) RelocalizeHeading(heading float64) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.pose.Heading = heading
+}
+
+// RelocalizeCommand returns a command which corrects the pose using a function of the current pose.
+func (o *Odometry) RelocalizeCommand(f func(pose Pose2d) Pose2d) *Command {
+	return NewFuncCommand(func() {
+		o.m.Lock()
+		defer o.m.Unlock()
+
+		o.pose = f(o.pose)
+	})
+}
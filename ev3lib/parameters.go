@@ -41,10 +41,94 @@ type EV3Color struct {
 	r, g, b float64
 }
 
+// NewColor creates a color with each channel from 0 to 1.
 func NewColor(r, g, b float64) EV3Color {
-	return EV3Color{r: r, g: g, b: b}
+	return EV3Color{r: Clamp(r, 0, 1), g: Clamp(g, 0, 1), b: Clamp(b, 0, 1)}
 }
 
+// RGB returns the channels of the color from 0 to 1.
+func (c EV3Color) RGB() (float64, float64, float64) {
+	return c.r, c.g, c.b
+}
+
+// WithBrightness scales the color by a brightness from 0 to 1.
+func (c EV3Color) WithBrightness(brightness float64) EV3Color {
+	return NewColor(c.r*brightness, c.g*brightness, c.b*brightness)
+}
+
+// Colors the red and green status lights can show.
+var (
+	OffLight    = NewColor(0, 0, 0)
+	GreenLight  = NewColor(0, 1, 0)
+	RedLight    = NewColor(1, 0, 0)
+	OrangeLight = NewColor(1, 0.25, 0)
+	AmberLight  = NewColor(1, 0.5, 0)
+	YellowLight = NewColor(1, 1, 0)
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// EV3 LED                                                                    //
+////////////////////////////////////////////////////////////////////////////////
+
+type EV3LED int
+
+const (
+	LeftLED EV3LED = iota
+	RightLED
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Lego Color                                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+// LegoColor is a color detected by the EV3 color sensor, numbered as by the sensor's COL-COLOR mode.
+type LegoColor int
+
+const (
+	NoColor LegoColor = iota
+	BlackColor
+	BlueColor
+	GreenColor
+	YellowColor
+	RedColor
+	WhiteColor
+	BrownColor
+)
+
+func (c LegoColor) String() string {
+	switch c {
+	case BlackColor:
+		return "black"
+	case BlueColor:
+		return "blue"
+	case GreenColor:
+		return "green"
+	case YellowColor:
+		return "yellow"
+	case RedColor:
+		return "red"
+	case WhiteColor:
+		return "white"
+	case BrownColor:
+		return "brown"
+	}
+	return "none"
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Menu Status                                                                //
+////////////////////////////////////////////////////////////////////////////////
+
+// MenuStatus is the state of the main menu, which is shown on the status lights.
+type MenuStatus int
+
+const (
+	MenuIdle MenuStatus = iota
+	MenuRunning
+	MenuFault
+	MenuLowBattery
+)
+
 ////////////////////////////////////////////////////////////////////////////////
 // EV3 Note                                                                   //
 ////////////////////////////////////////////////////////////////////////////////
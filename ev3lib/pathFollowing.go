@@ -0,0 +1,301 @@
+package ev3lib
+
+import (
+	"math"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Trajectory                                                                 //
+////////////////////////////////////////////////////////////////////////////////
+
+// TrajectoryState is the desired pose and motion at a time along a trajectory.
+// Curvature is in radians per unit of distance, positive when turning counterclockwise.
+// Velocity is negative when driving backwards.
+type TrajectoryState struct {
+	Time                              time.Duration
+	Pose                              Pose2d
+	Velocity, Acceleration, Curvature float64
+}
+
+// Trajectory is a path with timing which can be followed by a drivetrain.
+type Trajectory interface {
+	Duration() time.Duration
+	Sample(t time.Duration) TrajectoryState
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Pure Pursuit                                                               //
+////////////////////////////////////////////////////////////////////////////////
+
+// PurePursuitConfig sets how a path of waypoints is followed.
+// Distances and velocities are in the units of the drivetrain, e.g. millimetres and millimetres per second.
+type PurePursuitConfig struct {
+	// Lookahead is the distance along the path to the point the robot steers towards.
+	// Longer lookaheads give smoother but less accurate paths.
+	Lookahead float64
+
+	MaxVelocity, MaxAcceleration float64
+
+	// MinVelocity keeps the robot moving while slowing down for the end of the path.
+	MinVelocity float64
+
+	// MaxCentripetalAcceleration slows the robot in tight turns, 0 disables the limit.
+	MaxCentripetalAcceleration float64
+
+	// EndTolerance is how close to the last waypoint the robot must get to finish.
+	// A robot which misses the tolerance finishes once it drives past the end of the path.
+	EndTolerance float64
+
+	// Reversed drives the path backwards.
+	Reversed bool
+}
+
+// DefaultPurePursuitConfig returns a config with a min velocity of 10% of the max, an end tolerance of 10 and no centripetal limit.
+func DefaultPurePursuitConfig(lookahead, maxVelocity, maxAcceleration float64) PurePursuitConfig {
+	return PurePursuitConfig{
+		Lookahead:       lookahead,
+		MaxVelocity:     maxVelocity,
+		MaxAcceleration: maxAcceleration,
+		MinVelocity:     maxVelocity * 0.1,
+		EndTolerance:    10,
+	}
+}
+
+type purePursuitCommand struct {
+	DefaultCommand
+
+	waypoints []Pose2d
+	config    PurePursuitConfig
+
+	path    []Pose2d
+	lengths []float64
+
+	segment  int
+	velocity float64
+	lastTime time.Time
+	done     bool
+
+	d *DifferentialDrive
+	o *Odometry
+}
+
+func (c *purePursuitCommand) Init() {
+	c.o.Update()
+	pose := c.o.Pose()
+
+	// Start the path from the robot so the first waypoint is approached directly
+	c.path = c.path[:0]
+	if len(c.waypoints) == 0 || pose.DistanceTo(c.waypoints[0]) > c.config.EndTolerance {
+		c.path = append(c.path, pose)
+	}
+	c.path = append(c.path, c.waypoints...)
+
+	// Cumulative distance along the path to each point
+	c.lengths = c.lengths[:0]
+	total := 0.0
+	for i := range c.path {
+		if i > 0 {
+			total += c.path[i-1].DistanceTo(c.path[i])
+		}
+		c.lengths = append(c.lengths, total)
+	}
+
+	c.segment = 0
+	c.velocity = 0
+	c.lastTime = Now()
+	c.done = len(c.path) < 2
+}
+
+// project returns the distance along a segment closest to a pose and the distance from it.
+func (c *purePursuitCommand) project(i int, pose Pose2d) (along, dist float64) {
+	a, b := c.path[i], c.path[i+1]
+	dx, dy := b.X-a.X, b.Y-a.Y
+	length := math.Hypot(dx, dy)
+
+	t := 0.0
+	if length > 0 {
+		t = Clamp(((pose.X-a.X)*dx+(pose.Y-a.Y)*dy)/(length*length), 0, 1)
+	}
+
+	return c.lengths[i] + t*length, math.Hypot(a.X+t*dx-pose.X, a.Y+t*dy-pose.Y)
+}
+
+// pointAt returns the point a distance along the path, clamped to the ends.
+func (c *purePursuitCommand) pointAt(s float64) Pose2d {
+	for i := 0; i < len(c.path)-1; i++ {
+		if s <= c.lengths[i+1] || i == len(c.path)-2 {
+			length := c.lengths[i+1] - c.lengths[i]
+			t := 0.0
+			if length > 0 {
+				t = Clamp((s-c.lengths[i])/length, 0, 1)
+			}
+			a, b := c.path[i], c.path[i+1]
+			return Pose2d{X: a.X + t*(b.X-a.X), Y: a.Y + t*(b.Y-a.Y)}
+		}
+	}
+	return c.path[len(c.path)-1]
+}
+
+func (c *purePursuitCommand) Run() {
+	if c.done {
+		return
+	}
+
+	c.o.Update()
+	pose := c.o.Pose()
+
+	now := Now()
+	dt := now.Sub(c.lastTime).Seconds()
+	c.lastTime = now
+
+	// Find the closest point, only moving forwards so crossing paths are followed in order
+	along, best := c.project(c.segment, pose)
+	for i := c.segment + 1; i < len(c.path)-1; i++ {
+		a, dist := c.project(i, pose)
+		if dist < best {
+			along, best = a, dist
+			c.segment = i
+		}
+	}
+
+	heading := pose.HeadingRadians()
+	if c.config.Reversed {
+		heading += math.Pi
+	}
+
+	end := c.path[len(c.path)-1]
+	total := c.lengths[len(c.lengths)-1]
+	remaining := total - along
+	if pose.DistanceTo(end) <= c.config.EndTolerance && remaining <= c.config.Lookahead {
+		c.done = true
+		return
+	}
+
+	// Also finish once the robot has passed the end and is driving away from it,
+	// otherwise a robot which overshot the tolerance would circle back forever
+	last := c.path[len(c.path)-2]
+	past := (pose.X-end.X)*(end.X-last.X)+(pose.Y-end.Y)*(end.Y-last.Y) >= 0
+	away := math.Cos(heading)*(end.X-pose.X)+math.Sin(heading)*(end.Y-pose.Y) < 0
+	if c.segment == len(c.path)-2 && past && away {
+		c.done = true
+		return
+	}
+
+	// Curvature of the arc through the lookahead point, in the direction of travel
+	target := c.pointAt(along + c.config.Lookahead)
+	dx, dy := target.X-pose.X, target.Y-pose.Y
+	localY := -math.Sin(heading)*dx + math.Cos(heading)*dy
+	curvature := 0.0
+	if l2 := dx*dx + dy*dy; l2 > 0 {
+		curvature = 2 * localY / l2
+	}
+
+	// Slow down for the end of the path and tight turns, limiting acceleration
+	velocity := c.config.MaxVelocity
+	if c.config.MaxAcceleration > 0 {
+		velocity = min(velocity, math.Sqrt(2*c.config.MaxAcceleration*math.Max(pose.DistanceTo(end), 0)))
+	}
+	if c.config.MaxCentripetalAcceleration > 0 && curvature != 0 {
+		velocity = min(velocity, math.Sqrt(c.config.MaxCentripetalAcceleration/math.Abs(curvature)))
+	}
+	velocity = max(velocity, c.config.MinVelocity)
+	if c.config.MaxAcceleration > 0 {
+		velocity = min(velocity, c.velocity+c.config.MaxAcceleration*dt)
+	}
+	c.velocity = velocity
+
+	omega := velocity * curvature
+	if c.config.Reversed {
+		velocity = -velocity
+	}
+
+	c.d.SetWheelSpeeds(velocity-omega*c.d.trackWidth/2, velocity+omega*c.d.trackWidth/2)
+}
+
+func (c *purePursuitCommand) End(bool) {
+	c.d.Stop()
+}
+
+func (c *purePursuitCommand) IsDone() bool {
+	return c.done
+}
+
+// PurePursuit follows a path through waypoints, using the odometry to track the robot's pose.
+// Waypoint headings are ignored. The odometry is updated every loop.
+func (d *DifferentialDrive) PurePursuit(odometry *Odometry, waypoints []Pose2d, config PurePursuitConfig) *Command {
+	return NewCommand(&purePursuitCommand{waypoints: waypoints, config: config, d: d, o: odometry}).Requires(d.Subsystem)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// RAMSETE                                                                    //
+////////////////////////////////////////////////////////////////////////////////
+
+// RamseteConfig holds the gains of a RAMSETE controller.
+// B is like a proportional gain, in radians squared per distance squared, and Zeta from 0 to 1 is like a damping ratio.
+type RamseteConfig struct {
+	B, Zeta float64
+}
+
+// DefaultRamseteConfig returns the usual gains of B = 2 per metre squared and Zeta = 0.7, for distances in millimetres.
+func DefaultRamseteConfig() RamseteConfig {
+	return RamseteConfig{B: 2e-6, Zeta: 0.7}
+}
+
+type ramseteCommand struct {
+	DefaultCommand
+
+	trajectory Trajectory
+	config     RamseteConfig
+
+	start time.Time
+
+	d *DifferentialDrive
+	o *Odometry
+}
+
+func (c *ramseteCommand) Init() {
+	c.start = Now()
+}
+
+func (c *ramseteCommand) Run() {
+	c.o.Update()
+	pose := c.o.Pose()
+	desired := c.trajectory.Sample(Since(c.start))
+
+	// Error in the robot's frame
+	heading := pose.HeadingRadians()
+	dx, dy := desired.Pose.X-pose.X, desired.Pose.Y-pose.Y
+	ex := math.Cos(heading)*dx + math.Sin(heading)*dy
+	ey := -math.Sin(heading)*dx + math.Cos(heading)*dy
+	eHeading := math.Remainder(desired.Pose.HeadingRadians()-heading, 2*math.Pi)
+
+	vd := desired.Velocity
+	wd := desired.Velocity * desired.Curvature
+
+	k := 2 * c.config.Zeta * math.Sqrt(wd*wd+c.config.B*vd*vd)
+
+	sinc := 1.0
+	if math.Abs(eHeading) > 1e-9 {
+		sinc = math.Sin(eHeading) / eHeading
+	}
+
+	v := vd*math.Cos(eHeading) + k*ex
+	w := wd + k*eHeading + c.config.B*vd*sinc*ey
+
+	c.d.SetWheelSpeeds(v-w*c.d.trackWidth/2, v+w*c.d.trackWidth/2)
+}
+
+func (c *ramseteCommand) End(bool) {
+	c.d.Stop()
+}
+
+func (c *ramseteCommand) IsDone() bool {
+	return Since(c.start) >= c.trajectory.Duration()
+}
+
+// FollowTrajectory follows a trajectory with a RAMSETE controller, using the odometry to track the robot's pose.
+// Reversed trajectories have negative velocities. The odometry is updated every loop.
+func (d *DifferentialDrive) FollowTrajectory(odometry *Odometry, trajectory Trajectory, config RamseteConfig) *Command {
+	return NewCommand(&ramseteCommand{trajectory: trajectory, config: config, d: d, o: odometry}).Requires(d.Subsystem)
+}
@@ -0,0 +1,86 @@
+package ev3lib_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+func TestPurePursuit(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		waypoints []ev3lib.Pose2d
+		config    func(c *ev3lib.PurePursuitConfig)
+		tolerance float64
+	}{
+		{"straight", []ev3lib.Pose2d{{X: 600}}, nil, 15},
+		{"corner", []ev3lib.Pose2d{{X: 400}, {X: 400, Y: 400}}, nil, 15},
+		{"right turn", []ev3lib.Pose2d{{X: 400}, {X: 400, Y: -400}}, nil, 15},
+		{"reversed", []ev3lib.Pose2d{{X: -400}, {X: -400, Y: 300}}, func(c *ev3lib.PurePursuitConfig) {
+			c.Reversed = true
+		}, 15},
+		// Without the end tolerance the robot must finish as it passes the end instead of circling back
+		{"overshoot", []ev3lib.Pose2d{{X: 400}, {X: 400, Y: 400}}, func(c *ev3lib.PurePursuitConfig) {
+			c.EndTolerance = 0
+			c.MinVelocity = 200
+		}, 40},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c, w, d := newSimDrive(t, true)
+			o := d.NewOdometry()
+
+			config := ev3lib.DefaultPurePursuitConfig(100, 300, 600)
+			if test.config != nil {
+				test.config(&config)
+			}
+
+			runSim(t, c, w, d.PurePursuit(o, test.waypoints, config), 10*time.Second)
+
+			end := test.waypoints[len(test.waypoints)-1]
+			x, y, _ := w.Pose()
+			if dist := math.Hypot(x-end.X, y-end.Y); dist > test.tolerance {
+				t.Errorf("finished at (%v, %v), %v from the end (%v, %v)", x, y, dist, end.X, end.Y)
+			}
+		})
+	}
+}
+
+func TestFollowTrajectory(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		start     ev3lib.Pose2d
+		waypoints []ev3lib.Pose2d
+		reversed  bool
+	}{
+		{"on path", ev3lib.Pose2d{}, []ev3lib.Pose2d{{}, {X: 1200, Y: 300}}, false},
+		{"offset start", ev3lib.Pose2d{Y: 30, Heading: 10}, []ev3lib.Pose2d{{}, {X: 1200, Y: 300}}, false},
+		{"reversed", ev3lib.Pose2d{}, []ev3lib.Pose2d{{}, {X: -1200, Y: 300}}, true},
+		{"reversed offset start", ev3lib.Pose2d{X: 20, Y: -30}, []ev3lib.Pose2d{{}, {X: -1200, Y: 300}}, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c, w, d := newSimDrive(t, true)
+			w.SetPose(test.start.X, test.start.Y, test.start.Heading)
+			o := d.NewOdometry()
+			o.ResetPose(test.start)
+
+			config := ev3lib.NewTrajectoryConfig(300, 600)
+			config.Reversed = test.reversed
+			trajectory := ev3lib.GenerateTrajectory(test.waypoints, ev3lib.QuinticSpline, config)
+
+			// Tighter than the default gains so the offset starts converge within the path
+			ramsete := ev3lib.RamseteConfig{B: 2e-5, Zeta: 0.7}
+			runSim(t, c, w, d.FollowTrajectory(o, trajectory, ramsete), trajectory.Duration()+time.Second)
+
+			end := test.waypoints[len(test.waypoints)-1]
+			x, y, heading := w.Pose()
+			if dist := math.Hypot(x-end.X, y-end.Y); dist > 20 {
+				t.Errorf("finished at (%v, %v), %v from the end (%v, %v)", x, y, dist, end.X, end.Y)
+			}
+			if e := math.Remainder(heading-end.Heading, 360); math.Abs(e) > 5 {
+				t.Errorf("heading = %v, want %v", heading, end.Heading)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package ev3lib
+
+import (
+	"math"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Motion Profile                                                             //
+////////////////////////////////////////////////////////////////////////////////
+
+// MotionProfile holds the limits used to plan smooth moves from rest to rest.
+// Units are those of the position being controlled, e.g. degrees, degrees per second and so on.
+//
+// A max acceleration of 0 or less means the acceleration is unlimited, so the velocity steps straight to its maximum.
+// A max velocity of 0 or less means there is nothing to plan with, so the position steps straight to the end of the move.
+type MotionProfile struct {
+	maxVelocity, maxAcceleration, jerk float64
+}
+
+// NewTrapezoidProfile creates a profile which accelerates at a constant rate up to a maximum velocity.
+func NewTrapezoidProfile(maxVelocity, maxAcceleration float64) *MotionProfile {
+	return &MotionProfile{maxVelocity: maxVelocity, maxAcceleration: maxAcceleration}
+}
+
+// NewSCurveProfile creates a profile which also limits jerk, the rate of change of acceleration, for gentler starts and stops.
+// A jerk of 0 gives a trapezoid profile.
+func NewSCurveProfile(maxVelocity, maxAcceleration, jerk float64) *MotionProfile {
+	return &MotionProfile{maxVelocity: maxVelocity, maxAcceleration: maxAcceleration, jerk: jerk}
+}
+
+func (p *MotionProfile) MaxVelocity() float64 {
+	return p.maxVelocity
+}
+
+func (p *MotionProfile) MaxAcceleration() float64 {
+	return p.maxAcceleration
+}
+
+func (p *MotionProfile) Jerk() float64 {
+	return p.jerk
+}
+
+// ProfileState is a setpoint along a planned move.
+type ProfileState struct {
+	Position, Velocity, Acceleration float64
+}
+
+type profileSegment struct {
+	start    ProfileState
+	jerk     float64
+	duration float64
+}
+
+// ProfilePlan is a move over a set distance planned by a MotionProfile.
+type ProfilePlan struct {
+	distance float64
+	sign     float64
+
+	segments []profileSegment
+	duration float64
+}
+
+// Plan plans a move over a distance, which may be negative, starting and ending at rest.
+func (p *MotionProfile) Plan(distance float64) *ProfilePlan {
+	plan := &ProfilePlan{distance: distance, sign: 1}
+	if distance < 0 {
+		plan.sign = -1
+	}
+
+	d := math.Abs(distance)
+	if d == 0 {
+		return plan
+	}
+
+	// Each phase is a duration with either a jerk, or for trapezoids a fixed acceleration
+	type phase struct {
+		duration, jerk, acceleration float64
+		setAcceleration              bool
+	}
+	var phases []phase
+
+	// Limits which are not positive, including NaN, fall back to steps
+	if !(p.maxVelocity > 0) {
+		return plan
+	}
+	if !(p.maxAcceleration > 0) {
+		plan.duration = d / p.maxVelocity
+		plan.segments = []profileSegment{{start: ProfileState{Velocity: p.maxVelocity}, duration: plan.duration}}
+		return plan
+	}
+
+	switch {
+	case !(p.jerk > 0):
+		v := p.maxVelocity
+		ta := v / p.maxAcceleration
+		if v*ta > d {
+			v = math.Sqrt(d * p.maxAcceleration)
+			ta = v / p.maxAcceleration
+		}
+		cruise := (d - v*ta) / v
+
+		phases = []phase{
+			{duration: ta, acceleration: p.maxAcceleration, setAcceleration: true},
+			{duration: cruise, acceleration: 0, setAcceleration: true},
+			{duration: ta, acceleration: -p.maxAcceleration, setAcceleration: true},
+		}
+	default:
+		v := p.maxVelocity
+		tj, ta, accelDistance := p.sCurveAcceleration(v)
+
+		if 2*accelDistance > d {
+			// Cannot reach max velocity, search for the highest velocity that fits
+			lo, hi := 0.0, v
+			for i := 0; i < 60; i++ {
+				mid := (lo + hi) / 2
+				if _, _, dist := p.sCurveAcceleration(mid); 2*dist > d {
+					hi = mid
+				} else {
+					lo = mid
+				}
+			}
+			v = lo
+			tj, ta, accelDistance = p.sCurveAcceleration(v)
+		}
+		cruise := (d - 2*accelDistance) / v
+
+		phases = []phase{
+			{duration: tj, jerk: p.jerk},
+			{duration: ta, jerk: 0},
+			{duration: tj, jerk: -p.jerk},
+			{duration: cruise, jerk: 0},
+			{duration: tj, jerk: -p.jerk},
+			{duration: ta, jerk: 0},
+			{duration: tj, jerk: p.jerk},
+		}
+	}
+
+	state := ProfileState{}
+	for _, ph := range phases {
+		if ph.duration <= 0 {
+			continue
+		}
+
+		if ph.setAcceleration {
+			state.Acceleration = ph.acceleration
+		}
+
+		seg := profileSegment{start: state, jerk: ph.jerk, duration: ph.duration}
+		plan.segments = append(plan.segments, seg)
+		plan.duration += ph.duration
+
+		state = seg.sample(ph.duration)
+	}
+
+	return plan
+}
+
+// sCurveAcceleration returns the jerk time, constant acceleration time and distance needed to reach a velocity.
+func (p *MotionProfile) sCurveAcceleration(v float64) (tj, ta, distance float64) {
+	if v*p.jerk >= p.maxAcceleration*p.maxAcceleration {
+		tj = p.maxAcceleration / p.jerk
+		ta = v/p.maxAcceleration - tj
+	} else {
+		tj = math.Sqrt(v / p.jerk)
+		ta = 0
+	}
+
+	// Acceleration is symmetric, so the average velocity is half the final velocity
+	return tj, ta, v * (2*tj + ta) / 2
+}
+
+func (s profileSegment) sample(t float64) ProfileState {
+	return ProfileState{
+		Position:     s.start.Position + s.start.Velocity*t + s.start.Acceleration*t*t/2 + s.jerk*t*t*t/6,
+		Velocity:     s.start.Velocity + s.start.Acceleration*t + s.jerk*t*t/2,
+		Acceleration: s.start.Acceleration + s.jerk*t,
+	}
+}
+
+// Duration returns the time taken by the move.
+func (p *ProfilePlan) Duration() time.Duration {
+	return time.Duration(p.duration * float64(time.Second))
+}
+
+// Distance returns the distance covered by the move.
+func (p *ProfilePlan) Distance() float64 {
+	return p.distance
+}
+
+// Sample returns the setpoint at a time since the start of the move.
+func (p *ProfilePlan) Sample(t time.Duration) ProfileState {
+	s := t.Seconds()
+
+	if s <= 0 {
+		return ProfileState{}
+	}
+	if s >= p.duration {
+		return ProfileState{Position: p.distance}
+	}
+
+	for _, seg := range p.segments {
+		if s <= seg.duration {
+			state := seg.sample(s)
+			return ProfileState{
+				Position:     state.Position * p.sign,
+				Velocity:     state.Velocity * p.sign,
+				Acceleration: state.Acceleration * p.sign,
+			}
+		}
+		s -= seg.duration
+	}
+
+	return ProfileState{Position: p.distance}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Feedforward                                                                //
+////////////////////////////////////////////////////////////////////////////////
+
+// Feedforward estimates the power needed to move at a velocity and acceleration.
+// Ks is the power to overcome static friction, Kv the power per unit of velocity, and Ka the power per unit of acceleration.
+type Feedforward struct {
+	Ks, Kv, Ka float64
+}
+
+func NewFeedforward(ks, kv, ka float64) Feedforward {
+	return Feedforward{Ks: ks, Kv: kv, Ka: ka}
+}
+
+// Calculate returns the feedforward power for a velocity and acceleration.
+func (f Feedforward) Calculate(velocity, acceleration float64) float64 {
+	static := 0.0
+	if velocity > 0 {
+		static = f.Ks
+	} else if velocity < 0 {
+		static = -f.Ks
+	}
+
+	return static + f.Kv*velocity + f.Ka*acceleration
+}
@@ -0,0 +1,56 @@
+package ev3lib_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+func TestProfilePlan(t *testing.T) {
+	nan := math.NaN()
+
+	for _, test := range []struct {
+		name     string
+		profile  *ev3lib.MotionProfile
+		distance float64
+
+		duration    time.Duration
+		maxVelocity float64
+	}{
+		{"trapezoid", ev3lib.NewTrapezoidProfile(500, 1000), 1000, 2500 * time.Millisecond, 500},
+		{"triangle", ev3lib.NewTrapezoidProfile(500, 1000), 100, 632 * time.Millisecond, 316},
+		{"backwards", ev3lib.NewTrapezoidProfile(500, 1000), -1000, 2500 * time.Millisecond, 500},
+		{"s-curve", ev3lib.NewSCurveProfile(500, 1000, 5000), 1000, 2700 * time.Millisecond, 500},
+		{"no acceleration limit", ev3lib.NewTrapezoidProfile(500, 0), 1000, 2 * time.Second, 500},
+		{"negative acceleration", ev3lib.NewSCurveProfile(500, -1, 5000), 1000, 2 * time.Second, 500},
+		{"NaN acceleration", ev3lib.NewTrapezoidProfile(500, nan), 1000, 2 * time.Second, 500},
+		{"no velocity limit", ev3lib.NewTrapezoidProfile(0, 1000), 1000, 0, 0},
+		{"NaN velocity", ev3lib.NewSCurveProfile(nan, 1000, 5000), 1000, 0, 0},
+	} {
+		plan := test.profile.Plan(test.distance)
+
+		if got := plan.Duration(); (got - test.duration).Abs() > 5*time.Millisecond {
+			t.Errorf("%v: duration = %v, want %v", test.name, got, test.duration)
+		}
+
+		peak := 0.0
+		for ms := 0; ms <= int(plan.Duration().Milliseconds())+10; ms++ {
+			s := plan.Sample(time.Duration(ms) * time.Millisecond)
+			if math.IsNaN(s.Position) || math.IsInf(s.Position, 0) || math.IsNaN(s.Velocity) || math.IsInf(s.Velocity, 0) {
+				t.Fatalf("%v: setpoint %+v at %vms is not finite", test.name, s, ms)
+			}
+			peak = max(peak, math.Abs(s.Velocity))
+		}
+
+		if math.Abs(peak-test.maxVelocity) > 1 {
+			t.Errorf("%v: peak velocity = %v, want %v", test.name, peak, test.maxVelocity)
+		}
+
+		end := plan.Sample(plan.Duration() + time.Millisecond)
+		if end.Position != test.distance || end.Velocity != 0 {
+			t.Errorf("%v: ends at %+v, want at rest at %v", test.name, end, test.distance)
+		}
+	}
+}
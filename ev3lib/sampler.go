@@ -0,0 +1,524 @@
+package ev3lib
+
+import (
+	"sync"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Sampled Value                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+// SampledValue is the latest reading of a sensor value, with the time it was read.
+type SampledValue[T any] struct {
+	m sync.Mutex
+
+	value   T
+	time    time.Time
+	enabled bool
+	maxAge  time.Duration
+}
+
+func newSampledValue[T any](maxAge time.Duration, enabled bool) *SampledValue[T] {
+	return &SampledValue[T]{maxAge: maxAge, enabled: enabled}
+}
+
+// Get returns the latest value and the time it was read, which is zero if it has not been read yet.
+func (v *SampledValue[T]) Get() (T, time.Time) {
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	return v.value, v.time
+}
+
+// Value returns the latest value.
+func (v *SampledValue[T]) Value() T {
+	val, _ := v.Get()
+	return val
+}
+
+// Age returns how long ago the value was read.
+func (v *SampledValue[T]) Age() time.Duration {
+	_, t := v.Get()
+	return Since(t)
+}
+
+// IsStale returns whether the value has not been read within its max age, e.g. because the sensor is failing or unplugged.
+func (v *SampledValue[T]) IsStale() bool {
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	return v.time.IsZero() || Since(v.time) > v.maxAge
+}
+
+// SetMaxAge sets the age after which the value is stale. Defaults to three sampling intervals.
+func (v *SampledValue[T]) SetMaxAge(maxAge time.Duration) {
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	v.maxAge = maxAge
+}
+
+func (v *SampledValue[T]) set(val T) {
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	v.value = val
+	v.time = Now()
+}
+
+func (v *SampledValue[T]) isEnabled() bool {
+	v.m.Lock()
+	defer v.m.Unlock()
+
+	return v.enabled
+}
+
+// sample stores a reading if the value is being sampled.
+func (v *SampledValue[T]) sample(read func() T) {
+	if v.isEnabled() {
+		v.set(read())
+	}
+}
+
+// getOr returns the sampled value while the sampler is running, otherwise it reads the sensor directly.
+// Reading a value starts sampling it, so the first read is always direct.
+func (v *SampledValue[T]) getOr(s *SensorSampler, lock *sync.Mutex, read func() T) T {
+	running := s.isRunning()
+
+	v.m.Lock()
+	if running && v.enabled && !v.time.IsZero() {
+		defer v.m.Unlock()
+		return v.value
+	}
+	v.enabled = true
+	v.m.Unlock()
+
+	lock.Lock()
+	val := read()
+	lock.Unlock()
+
+	v.set(val)
+	return val
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Sensor Sampler                                                             //
+////////////////////////////////////////////////////////////////////////////////
+
+type sampledSensor struct {
+	interval time.Duration
+	poll     func()
+}
+
+// SensorSampler polls sensors in the background, each in its own goroutine, so commands can read their latest values without blocking.
+// Only the values that have been read are polled, which keeps sensors from switching modes more than needed.
+// Sampled sensors are read directly while the sampler is not running.
+//
+// Sampled sensors pass Port, Err and the gyro's RawAngle through to the sensor, so they can be wrapped by
+// ColorSensor, GyroSensor and HeadingTracker like the sensor itself. Err includes errors from background polling.
+//
+// Polling runs on real time rather than the ev3lib Clock, as sleeping on a testUtils.ManualClock advances it,
+// so background goroutines would race simulated time forward. Read sensors directly when simulating.
+type SensorSampler struct {
+	m sync.Mutex
+
+	sensors []sampledSensor
+	running bool
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func NewSensorSampler() *SensorSampler {
+	return &SensorSampler{}
+}
+
+func (s *SensorSampler) add(interval time.Duration, poll func()) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	sensor := sampledSensor{interval: interval, poll: poll}
+	s.sensors = append(s.sensors, sensor)
+	if s.running {
+		s.run(sensor)
+	}
+}
+
+func (s *SensorSampler) run(sensor sampledSensor) {
+	s.wg.Add(1)
+	go func(stop chan struct{}) {
+		defer s.wg.Done()
+
+		// Real time, see SensorSampler
+		ticker := time.NewTicker(sensor.interval)
+		defer ticker.Stop()
+
+		for {
+			sensor.poll()
+
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}(s.stop)
+}
+
+func (s *SensorSampler) isRunning() bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return s.running
+}
+
+// Start starts polling every sensor.
+func (s *SensorSampler) Start() {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.running {
+		return
+	}
+
+	s.running = true
+	s.stop = make(chan struct{})
+	for _, sensor := range s.sensors {
+		s.run(sensor)
+	}
+}
+
+// Stop stops polling and waits for the goroutines to finish. Sampled sensors are read directly until the sampler is started again.
+func (s *SensorSampler) Stop() {
+	s.m.Lock()
+	if !s.running {
+		s.m.Unlock()
+		return
+	}
+	s.running = false
+	close(s.stop)
+	s.m.Unlock()
+
+	s.wg.Wait()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Sampled Color Sensor                                                       //
+////////////////////////////////////////////////////////////////////////////////
+
+// SampledColorSensor is a color sensor read from a SensorSampler. Reflection is sampled from the start.
+type SampledColorSensor struct {
+	sensor  ColorSensorInterface
+	sampler *SensorSampler
+	read    sync.Mutex
+
+	AmbientValue, ReflectionValue *SampledValue[float64]
+	RGBValue                      *SampledValue[[3]float64]
+	ColorValue                    *SampledValue[LegoColor]
+}
+
+var _ ColorSensorInterface = &SampledColorSensor{}
+var _ ColorDetectorInterface = &SampledColorSensor{}
+
+// ColorSensor samples a color sensor at an interval.
+func (s *SensorSampler) ColorSensor(sensor ColorSensorInterface, interval time.Duration) *SampledColorSensor {
+	c := &SampledColorSensor{
+		sensor:          sensor,
+		sampler:         s,
+		AmbientValue:    newSampledValue[float64](3*interval, false),
+		ReflectionValue: newSampledValue[float64](3*interval, true),
+		RGBValue:        newSampledValue[[3]float64](3*interval, false),
+		ColorValue:      newSampledValue[LegoColor](3*interval, false),
+	}
+	s.add(interval, c.poll)
+	return c
+}
+
+func (c *SampledColorSensor) poll() {
+	c.read.Lock()
+	defer c.read.Unlock()
+
+	c.AmbientValue.sample(c.sensor.Ambient)
+	c.ReflectionValue.sample(c.sensor.Reflection)
+	c.RGBValue.sample(c.rgb)
+	c.ColorValue.sample(c.color)
+}
+
+// color reads the color detected by the sensor, or the nearest LEGO color to its reading if it can't detect colors.
+func (c *SampledColorSensor) color() LegoColor {
+	if d, ok := c.sensor.(ColorDetectorInterface); ok {
+		return d.Color()
+	}
+	color, _ := ClassifyLegoColor(c.sensor.GetRGB())
+	return color
+}
+
+func (c *SampledColorSensor) rgb() [3]float64 {
+	r, g, b := c.sensor.GetRGB()
+	return [3]float64{r, g, b}
+}
+
+func (c *SampledColorSensor) Ambient() float64 {
+	return c.AmbientValue.getOr(c.sampler, &c.read, c.sensor.Ambient)
+}
+
+func (c *SampledColorSensor) Reflection() float64 {
+	return c.ReflectionValue.getOr(c.sampler, &c.read, c.sensor.Reflection)
+}
+
+func (c *SampledColorSensor) GetRGB() (float64, float64, float64) {
+	rgb := c.RGBValue.getOr(c.sampler, &c.read, c.rgb)
+	return rgb[0], rgb[1], rgb[2]
+}
+
+func (c *SampledColorSensor) Color() LegoColor {
+	return c.ColorValue.getOr(c.sampler, &c.read, c.color)
+}
+
+// Port returns the port of the sensor, or an empty port if it does not have one.
+func (c *SampledColorSensor) Port() EV3Port {
+	return portOf(c.sensor)
+}
+
+// Err returns the first error reading the sensor since the last call to Err, including errors while polling.
+func (c *SampledColorSensor) Err() error {
+	return errOf(c.sensor)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Sampled Gyro Sensor                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// SampledGyroSensor is a gyro read from a SensorSampler. The angle and rate are sampled together.
+type SampledGyroSensor struct {
+	sensor  GyroSensorInterface
+	sampler *SensorSampler
+	read    sync.Mutex
+
+	AngleRateValue *SampledValue[[2]float64]
+}
+
+var _ GyroSensorInterface = &SampledGyroSensor{}
+
+// GyroSensor samples a gyro at an interval.
+func (s *SensorSampler) GyroSensor(sensor GyroSensorInterface, interval time.Duration) *SampledGyroSensor {
+	g := &SampledGyroSensor{sensor: sensor, sampler: s, AngleRateValue: newSampledValue[[2]float64](3*interval, true)}
+	s.add(interval, g.poll)
+	return g
+}
+
+func (g *SampledGyroSensor) poll() {
+	g.read.Lock()
+	defer g.read.Unlock()
+
+	g.AngleRateValue.sample(g.angleRate)
+}
+
+func (g *SampledGyroSensor) angleRate() [2]float64 {
+	angle, rate := g.sensor.AngleRate()
+	return [2]float64{angle, rate}
+}
+
+func (g *SampledGyroSensor) Rate() float64 {
+	_, rate := g.AngleRate()
+	return rate
+}
+
+func (g *SampledGyroSensor) Angle() float64 {
+	angle, _ := g.AngleRate()
+	return angle
+}
+
+func (g *SampledGyroSensor) AngleRate() (float64, float64) {
+	v := g.AngleRateValue.getOr(g.sampler, &g.read, g.angleRate)
+	return v[0], v[1]
+}
+
+// ResetAngle resets the gyro and samples it straight away, so the next read reflects the new angle.
+func (g *SampledGyroSensor) ResetAngle(angle float64) {
+	g.read.Lock()
+	defer g.read.Unlock()
+
+	g.sensor.ResetAngle(angle)
+	g.AngleRateValue.set(g.angleRate())
+}
+
+// Calibrate calibrates the gyro, blocking sampling while it does.
+func (g *SampledGyroSensor) Calibrate() {
+	g.read.Lock()
+	defer g.read.Unlock()
+
+	g.sensor.Calibrate()
+	g.AngleRateValue.set(g.angleRate())
+}
+
+// Port returns the port of the sensor, or an empty port if it does not have one.
+func (g *SampledGyroSensor) Port() EV3Port {
+	return portOf(g.sensor)
+}
+
+// Err returns the first error reading the sensor since the last call to Err, including errors while polling.
+func (g *SampledGyroSensor) Err() error {
+	return errOf(g.sensor)
+}
+
+// RawAngle returns the angle last read from the gyro hardware, see GyroSensor.RawAngle.
+func (g *SampledGyroSensor) RawAngle() (float64, bool) {
+	if r, ok := g.sensor.(interface{ RawAngle() (float64, bool) }); ok {
+		return r.RawAngle()
+	}
+	return 0, false
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Sampled Infrared Sensor                                                    //
+////////////////////////////////////////////////////////////////////////////////
+
+// SampledInfraredSensor is an infrared sensor read from a SensorSampler. Distance is sampled, beacon buttons are read directly.
+type SampledInfraredSensor struct {
+	sensor  InfraredSensorInterface
+	sampler *SensorSampler
+	read    sync.Mutex
+
+	DistanceValue *SampledValue[float64]
+}
+
+var _ InfraredSensorInterface = &SampledInfraredSensor{}
+
+// InfraredSensor samples an infrared sensor at an interval.
+func (s *SensorSampler) InfraredSensor(sensor InfraredSensorInterface, interval time.Duration) *SampledInfraredSensor {
+	i := &SampledInfraredSensor{sensor: sensor, sampler: s, DistanceValue: newSampledValue[float64](3*interval, true)}
+	s.add(interval, i.poll)
+	return i
+}
+
+func (i *SampledInfraredSensor) poll() {
+	i.read.Lock()
+	defer i.read.Unlock()
+
+	i.DistanceValue.sample(i.sensor.Distance)
+}
+
+func (i *SampledInfraredSensor) Distance() float64 {
+	return i.DistanceValue.getOr(i.sampler, &i.read, i.sensor.Distance)
+}
+
+func (i *SampledInfraredSensor) Buttons(channel int) []BeaconButton {
+	i.read.Lock()
+	defer i.read.Unlock()
+
+	return i.sensor.Buttons(channel)
+}
+
+// Port returns the port of the sensor, or an empty port if it does not have one.
+func (i *SampledInfraredSensor) Port() EV3Port {
+	return portOf(i.sensor)
+}
+
+// Err returns the first error reading the sensor since the last call to Err, including errors while polling.
+func (i *SampledInfraredSensor) Err() error {
+	return errOf(i.sensor)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Sampled Touch Sensor                                                       //
+////////////////////////////////////////////////////////////////////////////////
+
+// SampledTouchSensor is a touch sensor read from a SensorSampler.
+type SampledTouchSensor struct {
+	sensor  TouchSensorInterface
+	sampler *SensorSampler
+	read    sync.Mutex
+
+	PressedValue *SampledValue[bool]
+}
+
+var _ TouchSensorInterface = &SampledTouchSensor{}
+
+// TouchSensor samples a touch sensor at an interval.
+func (s *SensorSampler) TouchSensor(sensor TouchSensorInterface, interval time.Duration) *SampledTouchSensor {
+	t := &SampledTouchSensor{sensor: sensor, sampler: s, PressedValue: newSampledValue[bool](3*interval, true)}
+	s.add(interval, t.poll)
+	return t
+}
+
+func (t *SampledTouchSensor) poll() {
+	t.read.Lock()
+	defer t.read.Unlock()
+
+	t.PressedValue.sample(t.sensor.IsPressed)
+}
+
+func (t *SampledTouchSensor) IsPressed() bool {
+	return t.PressedValue.getOr(t.sampler, &t.read, t.sensor.IsPressed)
+}
+
+// Port returns the port of the sensor, or an empty port if it does not have one.
+func (t *SampledTouchSensor) Port() EV3Port {
+	return portOf(t.sensor)
+}
+
+// Err returns the first error reading the sensor since the last call to Err, including errors while polling.
+func (t *SampledTouchSensor) Err() error {
+	return errOf(t.sensor)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Sampled Ultrasonic Sensor                                                  //
+////////////////////////////////////////////////////////////////////////////////
+
+// SampledUltrasonicSensor is an ultrasonic sensor read from a SensorSampler. Distance is sampled from the start.
+type SampledUltrasonicSensor struct {
+	sensor  UltrasonicSensorInterface
+	sampler *SensorSampler
+	read    sync.Mutex
+
+	DistanceValue, DistanceSilentValue *SampledValue[float64]
+	PresenceValue                      *SampledValue[bool]
+}
+
+var _ UltrasonicSensorInterface = &SampledUltrasonicSensor{}
+
+// UltrasonicSensor samples an ultrasonic sensor at an interval.
+func (s *SensorSampler) UltrasonicSensor(sensor UltrasonicSensorInterface, interval time.Duration) *SampledUltrasonicSensor {
+	u := &SampledUltrasonicSensor{
+		sensor:              sensor,
+		sampler:             s,
+		DistanceValue:       newSampledValue[float64](3*interval, true),
+		DistanceSilentValue: newSampledValue[float64](3*interval, false),
+		PresenceValue:       newSampledValue[bool](3*interval, false),
+	}
+	s.add(interval, u.poll)
+	return u
+}
+
+func (u *SampledUltrasonicSensor) poll() {
+	u.read.Lock()
+	defer u.read.Unlock()
+
+	u.DistanceValue.sample(u.sensor.Distance)
+	u.DistanceSilentValue.sample(u.sensor.DistanceSilent)
+	u.PresenceValue.sample(u.sensor.Presence)
+}
+
+func (u *SampledUltrasonicSensor) Distance() float64 {
+	return u.DistanceValue.getOr(u.sampler, &u.read, u.sensor.Distance)
+}
+
+func (u *SampledUltrasonicSensor) DistanceSilent() float64 {
+	return u.DistanceSilentValue.getOr(u.sampler, &u.read, u.sensor.DistanceSilent)
+}
+
+func (u *SampledUltrasonicSensor) Presence() bool {
+	return u.PresenceValue.getOr(u.sampler, &u.read, u.sensor.Presence)
+}
+
+// Port returns the port of the sensor, or an empty port if it does not have one.
+func (u *SampledUltrasonicSensor) Port() EV3Port {
+	return portOf(u.sensor)
+}
+
+// Err returns the first error reading the sensor since the last call to Err, including errors while polling.
+func (u *SampledUltrasonicSensor) Err() error {
+	return errOf(u.sensor)
+}
@@ -0,0 +1,171 @@
+package ev3lib_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+// countingTouchSensor counts how often it is read.
+type countingTouchSensor struct {
+	m       sync.Mutex
+	pressed bool
+	reads   int
+}
+
+func (s *countingTouchSensor) IsPressed() bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.reads++
+	return s.pressed
+}
+
+func (s *countingTouchSensor) set(pressed bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.pressed = pressed
+}
+
+func (s *countingTouchSensor) count() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return s.reads
+}
+
+func TestSamplerReadsDirectlyWhenStopped(t *testing.T) {
+	sensor := &countingTouchSensor{}
+	sampler := ev3lib.NewSensorSampler()
+	sampled := sampler.TouchSensor(sensor, time.Hour)
+
+	for i, pressed := range []bool{false, true, false} {
+		sensor.set(pressed)
+		if got := sampled.IsPressed(); got != pressed {
+			t.Errorf("read %d = %v, want %v", i, got, pressed)
+		}
+	}
+	if got := sensor.count(); got != 3 {
+		t.Errorf("sensor read %d times, want 3", got)
+	}
+}
+
+func TestSamplerCachesWhileRunning(t *testing.T) {
+	sensor := &countingTouchSensor{}
+	sampler := ev3lib.NewSensorSampler()
+	sampled := sampler.TouchSensor(sensor, time.Millisecond)
+
+	sampler.Start()
+	defer sampler.Stop()
+
+	// Wait for the background sampling to see a change
+	sensor.set(true)
+	deadline := time.Now().Add(time.Second)
+	for !sampled.IsPressed() {
+		if time.Now().After(deadline) {
+			t.Fatal("sampled value never updated")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	sampler.Stop()
+	sensor.set(false)
+
+	// Once stopped, reads go to the sensor again
+	reads := sensor.count()
+	if sampled.IsPressed() {
+		t.Error("stopped sampler returned a stale value")
+	}
+	if got := sensor.count(); got != reads+1 {
+		t.Errorf("sensor read %d times after stopping, want 1", got-reads)
+	}
+}
+
+// countingColorSensor counts how often each value is read, as reading a different value switches the sensor's mode.
+type countingColorSensor struct {
+	m     sync.Mutex
+	reads map[string]int
+}
+
+func (s *countingColorSensor) read(value string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.reads[value]++
+}
+
+func (s *countingColorSensor) count(value string) int {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return s.reads[value]
+}
+
+func (s *countingColorSensor) Ambient() float64 {
+	s.read("ambient")
+	return 0
+}
+
+func (s *countingColorSensor) Reflection() float64 {
+	s.read("reflection")
+	return 0.5
+}
+
+func (s *countingColorSensor) GetRGB() (float64, float64, float64) {
+	s.read("rgb")
+	return 0, 0, 0
+}
+
+func (s *countingColorSensor) Color() ev3lib.LegoColor {
+	s.read("color")
+	return ev3lib.NoColor
+}
+
+func TestSamplerPollsReadValues(t *testing.T) {
+	sensor := &countingColorSensor{reads: map[string]int{}}
+	sampler := ev3lib.NewSensorSampler()
+	sampled := sampler.ColorSensor(sensor, time.Millisecond)
+
+	sampler.Start()
+	defer sampler.Stop()
+
+	// waitForReads waits until a value has been polled a few more times
+	waitForReads := func(value string) {
+		t.Helper()
+
+		target := sensor.count(value) + 5
+		deadline := time.Now().Add(time.Second)
+		for sensor.count(value) < target {
+			if time.Now().After(deadline) {
+				t.Fatalf("%v was not polled", value)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// Only reflection is polled from the start
+	waitForReads("reflection")
+	for _, value := range []string{"ambient", "rgb", "color"} {
+		if n := sensor.count(value); n != 0 {
+			t.Errorf("%v read %v times before being asked for, want 0", value, n)
+		}
+	}
+
+	// Reading a value starts polling it, leaving the others alone
+	sampled.GetRGB()
+	waitForReads("rgb")
+	for _, value := range []string{"ambient", "color"} {
+		if n := sensor.count(value); n != 0 {
+			t.Errorf("%v read %v times after reading rgb, want 0", value, n)
+		}
+	}
+
+	sampled.Color()
+	waitForReads("color")
+	if n := sensor.count("ambient"); n != 0 {
+		t.Errorf("ambient read %v times after reading color, want 0", n)
+	}
+}
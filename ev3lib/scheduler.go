@@ -0,0 +1,172 @@
+package ev3lib
+
+import "slices"
+
+////////////////////////////////////////////////////////////////////////////////
+// Subsystem                                                                  //
+////////////////////////////////////////////////////////////////////////////////
+
+// Subsystem represents a piece of hardware, such as a drivetrain or an arm, which only one command may use at a time.
+type Subsystem struct {
+	name string
+}
+
+// NewSubsystem creates a new subsystem with a name used for logging.
+func NewSubsystem(name string) *Subsystem {
+	return &Subsystem{name: name}
+}
+
+func (s *Subsystem) Name() string {
+	return s.name
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Scheduler                                                                  //
+////////////////////////////////////////////////////////////////////////////////
+
+// Scheduler runs commands while making sure no two commands use the same subsystem.
+type Scheduler struct {
+	scheduled []*Command
+
+	requirements map[*Subsystem]*Command
+
+	subsystems      []*Subsystem
+	defaultCommands map[*Subsystem]*Command
+
+	bindings []func()
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		scheduled:       make([]*Command, 0),
+		requirements:    map[*Subsystem]*Command{},
+		subsystems:      make([]*Subsystem, 0),
+		defaultCommands: map[*Subsystem]*Command{},
+		bindings:        make([]func(), 0),
+	}
+}
+
+// Schedule initialises and starts running commands.
+// Any command currently using a subsystem required by a new command will be interrupted,
+// unless it is uninterruptible, in which case the new command is not scheduled.
+func (s *Scheduler) Schedule(commands ...*Command) {
+	for _, c := range commands {
+		if s.IsScheduled(c) || !s.canInterrupt(c) {
+			continue
+		}
+
+		for _, r := range c.Requirements() {
+			if current, found := s.requirements[r]; found {
+				s.end(current, true)
+			}
+		}
+
+		c.Init()
+
+		s.scheduled = append(s.scheduled, c)
+		for _, r := range c.Requirements() {
+			s.requirements[r] = c
+		}
+	}
+}
+
+// canInterrupt returns whether every command using a subsystem required by c may be interrupted.
+func (s *Scheduler) canInterrupt(c *Command) bool {
+	for _, r := range c.Requirements() {
+		if current, found := s.requirements[r]; found && !current.IsInterruptible() {
+			return false
+		}
+	}
+	return true
+}
+
+// Cancel interrupts commands if they are scheduled.
+func (s *Scheduler) Cancel(commands ...*Command) {
+	for _, c := range commands {
+		if s.IsScheduled(c) {
+			s.end(c, true)
+		}
+	}
+}
+
+// CancelAll interrupts every scheduled command.
+func (s *Scheduler) CancelAll() {
+	s.Cancel(slices.Clone(s.scheduled)...)
+}
+
+// IsScheduled returns whether a command is currently running on the scheduler.
+func (s *Scheduler) IsScheduled(c *Command) bool {
+	return slices.Contains(s.scheduled, c)
+}
+
+// Requiring returns the command currently using a subsystem, or nil if it is free.
+func (s *Scheduler) Requiring(subsystem *Subsystem) *Command {
+	return s.requirements[subsystem]
+}
+
+// SetDefaultCommand sets a command to be run whenever no other command needs the subsystem.
+// The command will be made to require the subsystem if it does not already.
+func (s *Scheduler) SetDefaultCommand(subsystem *Subsystem, c *Command) {
+	c.Requires(subsystem)
+
+	if !slices.Contains(s.subsystems, subsystem) {
+		s.subsystems = append(s.subsystems, subsystem)
+	}
+
+	if current, found := s.defaultCommands[subsystem]; found {
+		s.Cancel(current)
+	}
+	s.defaultCommands[subsystem] = c
+}
+
+// DefaultCommand returns the default command of a subsystem, or nil if there is none.
+func (s *Scheduler) DefaultCommand(subsystem *Subsystem) *Command {
+	return s.defaultCommands[subsystem]
+}
+
+// Run calls periodic functions and polls all trigger bindings, then runs one iteration of every scheduled command, ending those that are done.
+// Default commands are then started for any subsystems that are free.
+// Periodic functions are skipped when the scheduler is run inside RunCommand, RunTimedCommand or the main menu, as they already call them.
+func (s *Scheduler) Run() {
+	if runnerDepth == 0 {
+		runPeriodics()
+	}
+
+	for _, b := range s.bindings {
+		b()
+	}
+
+	for _, c := range slices.Clone(s.scheduled) {
+		// Command may have been cancelled by another command this iteration
+		if !s.IsScheduled(c) {
+			continue
+		}
+
+		c.Run()
+
+		if c.IsDone() {
+			s.end(c, false)
+		}
+	}
+
+	for _, sub := range s.subsystems {
+		if _, found := s.requirements[sub]; found {
+			continue
+		}
+
+		if c := s.defaultCommands[sub]; c != nil {
+			s.Schedule(c)
+		}
+	}
+}
+
+func (s *Scheduler) end(c *Command, interrupted bool) {
+	s.scheduled = slices.DeleteFunc(s.scheduled, func(o *Command) bool { return o == c })
+	for _, r := range c.Requirements() {
+		if s.requirements[r] == c {
+			delete(s.requirements, r)
+		}
+	}
+
+	c.End(interrupted)
+}
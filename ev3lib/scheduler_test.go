@@ -0,0 +1,202 @@
+package ev3lib_test
+
+import (
+	"testing"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+// recordCommand records how it was run, finishing after `n` iterations, or never if `n` is 0.
+type recordCommand struct {
+	n int
+
+	inits, runs, ends int
+	interrupted       bool
+}
+
+func (c *recordCommand) Init() {
+	c.inits++
+	c.runs = 0
+}
+
+func (c *recordCommand) Run() {
+	c.runs++
+}
+
+func (c *recordCommand) End(interrupted bool) {
+	c.ends++
+	c.interrupted = interrupted
+}
+
+func (c *recordCommand) IsDone() bool {
+	return c.n > 0 && c.runs >= c.n
+}
+
+func newRecordCommand(n int, requirements ...*ev3lib.Subsystem) (*ev3lib.Command, *recordCommand) {
+	r := &recordCommand{n: n}
+	return ev3lib.NewCommand(r).Requires(requirements...), r
+}
+
+func TestSchedulerRequirements(t *testing.T) {
+	s := ev3lib.NewScheduler()
+	drive, arm := ev3lib.NewSubsystem("drive"), ev3lib.NewSubsystem("arm")
+
+	first, firstRec := newRecordCommand(0, drive)
+	other, otherRec := newRecordCommand(0, arm)
+	s.Schedule(first, other)
+	s.Run()
+
+	if s.Requiring(drive) != first || s.Requiring(arm) != other {
+		t.Fatal("commands do not hold their subsystems")
+	}
+
+	second, secondRec := newRecordCommand(0, drive)
+	s.Schedule(second)
+
+	if firstRec.ends != 1 || !firstRec.interrupted {
+		t.Errorf("first command ended %d times, interrupted %v, want once and interrupted", firstRec.ends, firstRec.interrupted)
+	}
+	if s.IsScheduled(first) || !s.IsScheduled(second) || s.Requiring(drive) != second {
+		t.Error("second command did not replace the first")
+	}
+	if secondRec.inits != 1 {
+		t.Errorf("second command initialised %d times, want 1", secondRec.inits)
+	}
+
+	// Commands on other subsystems are left running
+	s.Run()
+	if otherRec.ends != 0 || otherRec.runs != 2 {
+		t.Errorf("command on another subsystem ended %d times after %d runs, want 0 and 2", otherRec.ends, otherRec.runs)
+	}
+
+	// Scheduling a running command again does nothing
+	s.Schedule(second)
+	if secondRec.inits != 1 || secondRec.ends != 0 {
+		t.Errorf("rescheduled command initialised %d times and ended %d times, want 1 and 0", secondRec.inits, secondRec.ends)
+	}
+}
+
+func TestSchedulerUninterruptible(t *testing.T) {
+	s := ev3lib.NewScheduler()
+	drive := ev3lib.NewSubsystem("drive")
+
+	first, firstRec := newRecordCommand(0, drive)
+	first.Uninterruptible()
+	s.Schedule(first)
+
+	second, secondRec := newRecordCommand(0, drive)
+	s.Schedule(second)
+
+	if firstRec.ends != 0 || !s.IsScheduled(first) {
+		t.Error("uninterruptible command was interrupted")
+	}
+	if secondRec.inits != 0 || s.IsScheduled(second) {
+		t.Error("command was scheduled over an uninterruptible command")
+	}
+
+	// Cancelling still ends it, freeing the subsystem
+	s.Cancel(first)
+	if firstRec.ends != 1 || !firstRec.interrupted {
+		t.Errorf("cancelled command ended %d times, interrupted %v, want once and interrupted", firstRec.ends, firstRec.interrupted)
+	}
+
+	s.Schedule(second)
+	if !s.IsScheduled(second) {
+		t.Error("command not scheduled after the uninterruptible command was cancelled")
+	}
+}
+
+func TestSchedulerFinish(t *testing.T) {
+	s := ev3lib.NewScheduler()
+	drive := ev3lib.NewSubsystem("drive")
+
+	c, rec := newRecordCommand(3, drive)
+	s.Schedule(c)
+
+	for i := 0; i < 5; i++ {
+		s.Run()
+	}
+
+	if rec.runs != 3 || rec.ends != 1 || rec.interrupted {
+		t.Errorf("command ran %d times and ended %d times, interrupted %v, want 3, once and not interrupted", rec.runs, rec.ends, rec.interrupted)
+	}
+	if s.IsScheduled(c) || s.Requiring(drive) != nil {
+		t.Error("finished command still scheduled")
+	}
+}
+
+func TestSchedulerDefaultCommand(t *testing.T) {
+	s := ev3lib.NewScheduler()
+	drive := ev3lib.NewSubsystem("drive")
+
+	def, defRec := newRecordCommand(0)
+	s.SetDefaultCommand(drive, def)
+
+	s.Run()
+	if !s.IsScheduled(def) || s.Requiring(drive) != def {
+		t.Fatal("default command not started on a free subsystem")
+	}
+
+	c, _ := newRecordCommand(2, drive)
+	s.Schedule(c)
+	if defRec.ends != 1 || !defRec.interrupted {
+		t.Errorf("default command ended %d times, interrupted %v, want once and interrupted", defRec.ends, defRec.interrupted)
+	}
+
+	// The command finishes on the second run, then the default command is restarted
+	s.Run()
+	if s.IsScheduled(def) {
+		t.Error("default command restarted while the subsystem is in use")
+	}
+	s.Run()
+	if !s.IsScheduled(def) || defRec.inits != 2 {
+		t.Errorf("default command initialised %d times, want it restarted", defRec.inits)
+	}
+
+	// Replacing the default command cancels the old one
+	replacement, _ := newRecordCommand(0)
+	s.SetDefaultCommand(drive, replacement)
+	if s.IsScheduled(def) || defRec.ends != 2 {
+		t.Error("replaced default command not cancelled")
+	}
+
+	s.Run()
+	if s.DefaultCommand(drive) != replacement || !s.IsScheduled(replacement) {
+		t.Error("replacement default command not started")
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	s := ev3lib.NewScheduler()
+
+	a, aRec := newRecordCommand(0, ev3lib.NewSubsystem("a"))
+	b, bRec := newRecordCommand(0, ev3lib.NewSubsystem("b"))
+	c, cRec := newRecordCommand(0)
+
+	// Cancelling a command which is not scheduled does nothing
+	s.Cancel(a)
+	if aRec.ends != 0 {
+		t.Errorf("unscheduled command ended %d times, want 0", aRec.ends)
+	}
+
+	s.Schedule(a, b, c)
+	s.Cancel(a)
+	if aRec.ends != 1 || !aRec.interrupted || s.IsScheduled(a) {
+		t.Error("cancelled command not interrupted")
+	}
+
+	s.CancelAll()
+	for _, rec := range []*recordCommand{bRec, cRec} {
+		if rec.ends != 1 || !rec.interrupted {
+			t.Errorf("command ended %d times, interrupted %v by CancelAll, want once and interrupted", rec.ends, rec.interrupted)
+		}
+	}
+	if s.IsScheduled(b) || s.IsScheduled(c) {
+		t.Error("commands still scheduled after CancelAll")
+	}
+
+	s.Run()
+	if bRec.runs != 0 || cRec.runs != 0 {
+		t.Error("cancelled commands were run")
+	}
+}
@@ -1,15 +1,72 @@
 package ev3lib
 
+// errOf returns and clears the first read error of a device, if the device reports errors.
+// Readings which have an XErr method, such as AngleErr, use the error of that read from the device if it has the method,
+// falling back to errOf after the read for devices which only report errors through Err.
+func errOf(device any) error {
+	if e, ok := device.(interface{ Err() error }); ok {
+		return e.Err()
+	}
+	return nil
+}
+
+// portOf returns the port of a device, or an empty port if the device does not have one.
+func portOf(device any) EV3Port {
+	if p, ok := device.(interface{ Port() EV3Port }); ok {
+		return p.Port()
+	}
+	return ""
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Color Sensor                                                               //
 ////////////////////////////////////////////////////////////////////////////////
 
+// ColorSensor wraps a color sensor, normalizing its readings once it has been calibrated.
 type ColorSensor struct {
 	ColorSensorInterface
+
+	calibration *ColorCalibration
 }
 
 func NewColorSensorBase(c ColorSensorInterface) *ColorSensor {
-	return &ColorSensor{c}
+	return &ColorSensor{ColorSensorInterface: c}
+}
+
+// Port returns the port the sensor is connected to, or an empty port if the sensor does not have one.
+func (c *ColorSensor) Port() EV3Port {
+	return portOf(c.ColorSensorInterface)
+}
+
+// Err returns the first error reading the sensor since the last call to Err.
+func (c *ColorSensor) Err() error {
+	return errOf(c.ColorSensorInterface)
+}
+
+func (c *ColorSensor) AmbientErr() (float64, error) {
+	if s, ok := c.ColorSensorInterface.(interface{ AmbientErr() (float64, error) }); ok {
+		return s.AmbientErr()
+	}
+	return c.Ambient(), c.Err()
+}
+
+// RawReflectionErr returns the reflection read from the sensor, ignoring calibration, and the error reading it.
+func (c *ColorSensor) RawReflectionErr() (float64, error) {
+	if s, ok := c.ColorSensorInterface.(interface{ ReflectionErr() (float64, error) }); ok {
+		return s.ReflectionErr()
+	}
+	return c.RawReflection(), c.Err()
+}
+
+// RawRGBErr returns the color read from the sensor, ignoring calibration, and the error reading it.
+func (c *ColorSensor) RawRGBErr() (float64, float64, float64, error) {
+	if s, ok := c.ColorSensorInterface.(interface {
+		GetRGBErr() (float64, float64, float64, error)
+	}); ok {
+		return s.GetRGBErr()
+	}
+	r, g, b := c.RawRGB()
+	return r, g, b, c.Err()
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -24,6 +81,44 @@ func NewGyroSensorBase(g GyroSensorInterface) *GyroSensor {
 	return &GyroSensor{g}
 }
 
+// Err returns the first error reading the sensor since the last call to Err.
+func (g *GyroSensor) Err() error {
+	return errOf(g.GyroSensorInterface)
+}
+
+func (g *GyroSensor) RateErr() (float64, error) {
+	if s, ok := g.GyroSensorInterface.(interface{ RateErr() (float64, error) }); ok {
+		return s.RateErr()
+	}
+	return g.Rate(), g.Err()
+}
+
+func (g *GyroSensor) AngleErr() (float64, error) {
+	if s, ok := g.GyroSensorInterface.(interface{ AngleErr() (float64, error) }); ok {
+		return s.AngleErr()
+	}
+	return g.Angle(), g.Err()
+}
+
+func (g *GyroSensor) AngleRateErr() (float64, float64, error) {
+	if s, ok := g.GyroSensorInterface.(interface {
+		AngleRateErr() (float64, float64, error)
+	}); ok {
+		return s.AngleRateErr()
+	}
+	angle, rate := g.AngleRate()
+	return angle, rate, g.Err()
+}
+
+// RawAngle returns the angle last read from the gyro hardware, before overflows are unwrapped and resets applied.
+// Returns false if the gyro does not provide it.
+func (g *GyroSensor) RawAngle() (float64, bool) {
+	if r, ok := g.GyroSensorInterface.(interface{ RawAngle() (float64, bool) }); ok {
+		return r.RawAngle()
+	}
+	return 0, false
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Color Sensor                                                               //
 ////////////////////////////////////////////////////////////////////////////////
@@ -36,6 +131,27 @@ func NewInfraredSensorBase(i InfraredSensorInterface) *InfraredSensor {
 	return &InfraredSensor{i}
 }
 
+// Err returns the first error reading the sensor since the last call to Err.
+func (i *InfraredSensor) Err() error {
+	return errOf(i.InfraredSensorInterface)
+}
+
+func (i *InfraredSensor) DistanceErr() (float64, error) {
+	if s, ok := i.InfraredSensorInterface.(interface{ DistanceErr() (float64, error) }); ok {
+		return s.DistanceErr()
+	}
+	return i.Distance(), i.Err()
+}
+
+func (i *InfraredSensor) ButtonsErr(channel int) ([]BeaconButton, error) {
+	if s, ok := i.InfraredSensorInterface.(interface {
+		ButtonsErr(channel int) ([]BeaconButton, error)
+	}); ok {
+		return s.ButtonsErr(channel)
+	}
+	return i.Buttons(channel), i.Err()
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Color Sensor                                                               //
 ////////////////////////////////////////////////////////////////////////////////
@@ -48,6 +164,18 @@ func NewTouchSensorBase(t TouchSensorInterface) *TouchSensor {
 	return &TouchSensor{t}
 }
 
+// Err returns the first error reading the sensor since the last call to Err.
+func (t *TouchSensor) Err() error {
+	return errOf(t.TouchSensorInterface)
+}
+
+func (t *TouchSensor) IsPressedErr() (bool, error) {
+	if s, ok := t.TouchSensorInterface.(interface{ IsPressedErr() (bool, error) }); ok {
+		return s.IsPressedErr()
+	}
+	return t.IsPressed(), t.Err()
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Color Sensor                                                               //
 ////////////////////////////////////////////////////////////////////////////////
@@ -59,3 +187,29 @@ type UltrasonicSensor struct {
 func NewUltrasonicSensorBase(u UltrasonicSensorInterface) *UltrasonicSensor {
 	return &UltrasonicSensor{u}
 }
+
+// Err returns the first error reading the sensor since the last call to Err.
+func (u *UltrasonicSensor) Err() error {
+	return errOf(u.UltrasonicSensorInterface)
+}
+
+func (u *UltrasonicSensor) DistanceErr() (float64, error) {
+	if s, ok := u.UltrasonicSensorInterface.(interface{ DistanceErr() (float64, error) }); ok {
+		return s.DistanceErr()
+	}
+	return u.Distance(), u.Err()
+}
+
+func (u *UltrasonicSensor) DistanceSilentErr() (float64, error) {
+	if s, ok := u.UltrasonicSensorInterface.(interface{ DistanceSilentErr() (float64, error) }); ok {
+		return s.DistanceSilentErr()
+	}
+	return u.DistanceSilent(), u.Err()
+}
+
+func (u *UltrasonicSensor) PresenceErr() (bool, error) {
+	if s, ok := u.UltrasonicSensorInterface.(interface{ PresenceErr() (bool, error) }); ok {
+		return s.PresenceErr()
+	}
+	return u.Presence(), u.Err()
+}
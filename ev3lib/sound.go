@@ -0,0 +1,330 @@
+package ev3lib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Notes                                                                      //
+////////////////////////////////////////////////////////////////////////////////
+
+// Note is a parsed EV3Note.
+type Note struct {
+	// Frequency is the pitch in Hz, or 0 for a rest.
+	Frequency float64
+
+	// Beats is the length in quarter notes.
+	Beats float64
+
+	// Legato notes are held for their full length instead of being followed by a short gap.
+	Legato bool
+}
+
+var noteSemitones = map[byte]int{'C': 0, 'D': 2, 'E': 4, 'F': 5, 'G': 7, 'A': 9, 'B': 11}
+
+// Parse parses a note such as "C4/4", a quarter note middle C, or "R/8", an eighth note rest.
+// The pitch may be sharpened with # or flattened with b, e.g. "F#5/8", and the length is a fraction of a whole note.
+// A trailing . makes a dotted note one and a half times as long, and a trailing _ makes it legato, e.g. "G4/2._".
+func (n EV3Note) Parse() (Note, error) {
+	s := string(n)
+	if s == "" {
+		return Note{}, fmt.Errorf("empty note")
+	}
+
+	var note Note
+	if strings.HasSuffix(s, "_") {
+		note.Legato = true
+		s = strings.TrimSuffix(s, "_")
+	}
+	dotted := strings.HasSuffix(s, ".")
+	s = strings.TrimSuffix(s, ".")
+
+	pitch, length, found := strings.Cut(s, "/")
+	if !found {
+		return Note{}, fmt.Errorf("note %q has no length", n)
+	}
+
+	fraction, err := strconv.Atoi(length)
+	if err != nil || fraction <= 0 {
+		return Note{}, fmt.Errorf("note %q has invalid length %q", n, length)
+	}
+	note.Beats = 4 / float64(fraction)
+	if dotted {
+		note.Beats *= 1.5
+	}
+
+	if pitch == "" {
+		return Note{}, fmt.Errorf("note %q has no pitch", n)
+	}
+	if pitch == "R" {
+		return note, nil
+	}
+
+	semitone, found := noteSemitones[pitch[0]]
+	if !found {
+		return Note{}, fmt.Errorf("note %q has invalid name %q", n, pitch[:1])
+	}
+	pitch = pitch[1:]
+
+	if strings.HasPrefix(pitch, "#") {
+		semitone++
+		pitch = pitch[1:]
+	} else if strings.HasPrefix(pitch, "b") {
+		semitone--
+		pitch = pitch[1:]
+	}
+
+	octave, err := strconv.Atoi(pitch)
+	if err != nil {
+		return Note{}, fmt.Errorf("note %q has invalid octave %q", n, pitch)
+	}
+
+	// MIDI numbering, where A4 is 69 at 440 Hz
+	midi := (octave+1)*12 + semitone
+	note.Frequency = 440 * math.Pow(2, float64(midi-69)/12)
+
+	return note, nil
+}
+
+// ParseNotes parses every note, returning the first error.
+func ParseNotes(notes []EV3Note) ([]Note, error) {
+	parsed := make([]Note, len(notes))
+	for i, n := range notes {
+		var err error
+		if parsed[i], err = n.Parse(); err != nil {
+			return nil, err
+		}
+	}
+	return parsed, nil
+}
+
+// Timing returns how long the note sounds and the silence after it at a tempo in quarter notes per minute.
+func (n Note) Timing(tempo float64) (sound, gap time.Duration) {
+	length := time.Duration(n.Beats * 60 / tempo * float64(time.Second))
+	if n.Frequency == 0 {
+		return 0, length
+	}
+	if n.Legato {
+		return length, 0
+	}
+
+	sound = length * 7 / 8
+	return sound, length - sound
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// WAV                                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// WAV is 16 bit PCM audio, with channels interleaved.
+type WAV struct {
+	SampleRate int
+	Channels   int
+	Samples    []int16
+}
+
+// Duration returns the length of the audio.
+func (w *WAV) Duration() time.Duration {
+	if w.SampleRate == 0 || w.Channels == 0 {
+		return 0
+	}
+	return time.Duration(len(w.Samples)/w.Channels) * time.Second / time.Duration(w.SampleRate)
+}
+
+// Mono returns the audio with its channels averaged at a sample rate, resampling by the nearest sample.
+func (w *WAV) Mono(sampleRate int) []int16 {
+	if w.SampleRate <= 0 || w.Channels <= 0 || sampleRate <= 0 {
+		return nil
+	}
+
+	// 64 bit so a few seconds at 44.1 kHz do not overflow on 32 bit targets such as the EV3
+	frames := int64(len(w.Samples) / w.Channels)
+	out := make([]int16, frames*int64(sampleRate)/int64(w.SampleRate))
+
+	for i := range out {
+		frame := int(int64(i) * int64(w.SampleRate) / int64(sampleRate))
+		sum := 0
+		for c := 0; c < w.Channels; c++ {
+			sum += int(w.Samples[frame*w.Channels+c])
+		}
+		out[i] = int16(sum / w.Channels)
+	}
+	return out
+}
+
+// ReadWAV reads an 8 or 16 bit PCM WAV file.
+func ReadWAV(r io.Reader) (*WAV, error) {
+	var header struct {
+		RIFF [4]byte
+		Size uint32
+		WAVE [4]byte
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if string(header.RIFF[:]) != "RIFF" || string(header.WAVE[:]) != "WAVE" {
+		return nil, errors.New("not a WAV file")
+	}
+
+	var format struct {
+		AudioFormat   uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+	}
+	hasFormat := false
+
+	for {
+		var chunk struct {
+			ID   [4]byte
+			Size uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &chunk); err != nil {
+			return nil, err
+		}
+
+		// Chunks are padded to an even size
+		size := int64(chunk.Size) + int64(chunk.Size%2)
+
+		switch string(chunk.ID[:]) {
+		case "fmt ":
+			if err := binary.Read(r, binary.LittleEndian, &format); err != nil {
+				return nil, err
+			}
+			if _, err := io.CopyN(io.Discard, r, size-16); err != nil {
+				return nil, err
+			}
+			hasFormat = true
+
+		case "data":
+			if !hasFormat {
+				return nil, errors.New("WAV data before format")
+			}
+			if format.AudioFormat != 1 || format.Channels == 0 {
+				return nil, fmt.Errorf("unsupported WAV format %d", format.AudioFormat)
+			}
+
+			// Streamed WAVs, e.g. from espeak, may give the largest size instead of the real one
+			data, err := io.ReadAll(io.LimitReader(r, int64(chunk.Size)))
+			if err != nil {
+				return nil, err
+			}
+
+			w := &WAV{SampleRate: int(format.SampleRate), Channels: int(format.Channels)}
+			switch format.BitsPerSample {
+			case 8:
+				w.Samples = make([]int16, len(data))
+				for i, b := range data {
+					w.Samples[i] = int16(int(b)-128) << 8
+				}
+			case 16:
+				w.Samples = make([]int16, len(data)/2)
+				for i := range w.Samples {
+					w.Samples[i] = int16(binary.LittleEndian.Uint16(data[2*i:]))
+				}
+			default:
+				return nil, fmt.Errorf("unsupported WAV sample size %d", format.BitsPerSample)
+			}
+			return w, nil
+
+		default:
+			if _, err := io.CopyN(io.Discard, r, size); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// Write writes the audio as a 16 bit PCM WAV file.
+func (w *WAV) Write(wr io.Writer) error {
+	dataSize := uint32(2 * len(w.Samples))
+
+	header := struct {
+		RIFF          [4]byte
+		Size          uint32
+		WAVE          [4]byte
+		FmtID         [4]byte
+		FmtSize       uint32
+		AudioFormat   uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+		DataID        [4]byte
+		DataSize      uint32
+	}{
+		RIFF:          [4]byte{'R', 'I', 'F', 'F'},
+		Size:          36 + dataSize,
+		WAVE:          [4]byte{'W', 'A', 'V', 'E'},
+		FmtID:         [4]byte{'f', 'm', 't', ' '},
+		FmtSize:       16,
+		AudioFormat:   1,
+		Channels:      uint16(w.Channels),
+		SampleRate:    uint32(w.SampleRate),
+		ByteRate:      uint32(w.SampleRate * w.Channels * 2),
+		BlockAlign:    uint16(w.Channels * 2),
+		BitsPerSample: 16,
+		DataID:        [4]byte{'d', 'a', 't', 'a'},
+		DataSize:      dataSize,
+	}
+
+	if err := binary.Write(wr, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	return binary.Write(wr, binary.LittleEndian, w.Samples)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Text To Speech                                                             //
+////////////////////////////////////////////////////////////////////////////////
+
+// SpeechBackend turns text into audio for SpeakText.
+type SpeechBackend interface {
+	Synthesize(text string) (*WAV, error)
+}
+
+// EspeakSpeech synthesizes speech with the espeak program, which is installed on ev3dev.
+type EspeakSpeech struct {
+	// Voice is the espeak voice, e.g. "en" or "en+f3".
+	Voice string
+
+	// Speed is the speaking rate in words per minute.
+	Speed int
+}
+
+func (e EspeakSpeech) Synthesize(text string) (*WAV, error) {
+	var out, stderr bytes.Buffer
+
+	cmd := exec.Command("espeak", "--stdout", "-v", e.Voice, "-s", strconv.Itoa(e.Speed), text)
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("espeak: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return ReadWAV(&out)
+}
+
+var speechBackend SpeechBackend = EspeakSpeech{Voice: "en", Speed: 150}
+
+// SetSpeechBackend sets the backend used by SpeakText. Defaults to espeak.
+func SetSpeechBackend(s SpeechBackend) {
+	speechBackend = s
+}
+
+// GetSpeechBackend returns the backend used by SpeakText.
+func GetSpeechBackend() SpeechBackend {
+	return speechBackend
+}
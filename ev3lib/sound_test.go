@@ -0,0 +1,163 @@
+package ev3lib_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+func TestParseNote(t *testing.T) {
+	for _, test := range []struct {
+		note ev3lib.EV3Note
+		want ev3lib.Note
+	}{
+		{"A4/4", ev3lib.Note{Frequency: 440, Beats: 1}},
+		{"C4/4", ev3lib.Note{Frequency: 261.6256, Beats: 1}},
+		{"A5/8", ev3lib.Note{Frequency: 880, Beats: 0.5}},
+		{"F#5/8", ev3lib.Note{Frequency: 739.9888, Beats: 0.5}},
+		{"Bb3/2", ev3lib.Note{Frequency: 233.0819, Beats: 2}},
+		{"G4/2.", ev3lib.Note{Frequency: 391.9954, Beats: 3}},
+		{"G4/2._", ev3lib.Note{Frequency: 391.9954, Beats: 3, Legato: true}},
+		{"E2/1_", ev3lib.Note{Frequency: 82.4069, Beats: 4, Legato: true}},
+		{"R/8", ev3lib.Note{Beats: 0.5}},
+		{"R/4.", ev3lib.Note{Beats: 1.5}},
+	} {
+		got, err := test.note.Parse()
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", test.note, err)
+			continue
+		}
+		if math.Abs(got.Frequency-test.want.Frequency) > 1e-3 || got.Beats != test.want.Beats || got.Legato != test.want.Legato {
+			t.Errorf("%q = %+v, want %+v", test.note, got, test.want)
+		}
+	}
+}
+
+func TestParseInvalidNote(t *testing.T) {
+	for _, note := range []ev3lib.EV3Note{
+		"", "/4", "_", ".", "C4", "C4/", "C4/0", "C4/-2", "C4/x", "H4/4", "C/4", "C#/4", "Cx4/4", "r/4",
+	} {
+		if _, err := note.Parse(); err == nil {
+			t.Errorf("%q: expected an error", note)
+		}
+	}
+}
+
+func TestNoteTiming(t *testing.T) {
+	for _, test := range []struct {
+		note       ev3lib.Note
+		sound, gap time.Duration
+	}{
+		{ev3lib.Note{Frequency: 440, Beats: 1}, 875 * time.Millisecond, 125 * time.Millisecond},
+		{ev3lib.Note{Frequency: 440, Beats: 1, Legato: true}, time.Second, 0},
+		{ev3lib.Note{Beats: 2}, 0, 2 * time.Second},
+	} {
+		sound, gap := test.note.Timing(60)
+		if sound != test.sound || gap != test.gap {
+			t.Errorf("%+v timing = %v, %v, want %v, %v", test.note, sound, gap, test.sound, test.gap)
+		}
+	}
+}
+
+func TestWAVRoundTrip(t *testing.T) {
+	w := &ev3lib.WAV{SampleRate: 8000, Channels: 2, Samples: []int16{0, 1, -1, 32767, -32768, 100}}
+
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ev3lib.ReadWAV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SampleRate != w.SampleRate || got.Channels != w.Channels || !slices.Equal(got.Samples, w.Samples) {
+		t.Errorf("read %+v, want %+v", got, w)
+	}
+}
+
+// wavChunk returns a RIFF chunk, padded to an even size.
+func wavChunk(id string, data []byte) []byte {
+	chunk := append([]byte(id), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func TestReadWAV(t *testing.T) {
+	format := func(bits uint16) []byte {
+		b := binary.LittleEndian.AppendUint16(nil, 1)
+		b = binary.LittleEndian.AppendUint16(b, 1)
+		b = binary.LittleEndian.AppendUint32(b, 8000)
+		b = binary.LittleEndian.AppendUint32(b, 8000*uint32(bits/8))
+		b = binary.LittleEndian.AppendUint16(b, bits/8)
+		return binary.LittleEndian.AppendUint16(b, bits)
+	}
+	riff := func(chunks ...[]byte) []byte {
+		body := []byte("WAVE")
+		for _, c := range chunks {
+			body = append(body, c...)
+		}
+		return append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+	}
+
+	for _, test := range []struct {
+		name string
+		data []byte
+		want []int16
+		ok   bool
+	}{
+		{"8 bit", riff(wavChunk("fmt ", format(8)), wavChunk("data", []byte{128, 255, 0})), []int16{0, 127 << 8, -128 << 8}, true},
+		{"odd chunk skipped", riff(wavChunk("LIST", []byte{1, 2, 3}), wavChunk("fmt ", format(16)), wavChunk("data", []byte{1, 0})), []int16{1}, true},
+		{"data before format", riff(wavChunk("data", []byte{1, 0}), wavChunk("fmt ", format(16))), nil, false},
+		{"24 bit", riff(wavChunk("fmt ", format(24)), wavChunk("data", []byte{1, 2, 3})), nil, false},
+		{"not a WAV", []byte("RIFF\x04\x00\x00\x00AVI "), nil, false},
+		{"truncated", []byte("RIFF"), nil, false},
+	} {
+		w, err := ev3lib.ReadWAV(bytes.NewReader(test.data))
+		if (err == nil) != test.ok {
+			t.Errorf("%v: err = %v, want ok %v", test.name, err, test.ok)
+			continue
+		}
+		if test.ok && !slices.Equal(w.Samples, test.want) {
+			t.Errorf("%v: samples = %v, want %v", test.name, w.Samples, test.want)
+		}
+	}
+}
+
+func TestWAVMono(t *testing.T) {
+	stereo := &ev3lib.WAV{SampleRate: 4, Channels: 2, Samples: []int16{0, 10, 20, 30, -10, -30, 100, 100}}
+
+	for _, test := range []struct {
+		name       string
+		w          *ev3lib.WAV
+		sampleRate int
+		want       []int16
+	}{
+		{"averages channels", stereo, 4, []int16{5, 25, -20, 100}},
+		{"downsamples", stereo, 2, []int16{5, -20}},
+		{"upsamples", &ev3lib.WAV{SampleRate: 2, Channels: 1, Samples: []int16{1, 2}}, 4, []int16{1, 1, 2, 2}},
+		{"no sample rate", &ev3lib.WAV{Channels: 1, Samples: []int16{1}}, 4, nil},
+		{"no output rate", stereo, 0, nil},
+	} {
+		if got := test.w.Mono(test.sampleRate); !slices.Equal(got, test.want) {
+			t.Errorf("%v: Mono = %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	// frames*sampleRate overflows 32 bits for a minute at 44.1 kHz
+	long := &ev3lib.WAV{SampleRate: 44100, Channels: 1, Samples: make([]int16, 60*44100)}
+	if got := len(long.Mono(48000)); got != 60*48000 {
+		t.Errorf("resampled length = %d, want %d", got, 60*48000)
+	}
+	if d := long.Duration(); d != time.Minute {
+		t.Errorf("duration = %v, want 1m", d)
+	}
+}
@@ -0,0 +1,334 @@
+package testUtils
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Simulated Motor Model                                                      //
+////////////////////////////////////////////////////////////////////////////////
+
+// SimMotorModel describes the physical behaviour of a simulated motor.
+type SimMotorModel struct {
+	// MaxSpeed is the unloaded speed at full duty cycle in degrees per second.
+	MaxSpeed float64
+
+	// TimeConstant is the time taken to reach ~63% of a new target speed, modelling inertia.
+	TimeConstant time.Duration
+
+	// BrakeTimeConstant is the time constant of the deceleration when braking or holding.
+	BrakeTimeConstant time.Duration
+
+	// Friction is the deceleration in degrees per second squared when coasting.
+	Friction float64
+
+	// StaticDuty is the duty cycle from 0 to 1 needed to overcome static friction.
+	StaticDuty float64
+
+	// HoldGain is the target speed in degrees per second per degree of error when holding position.
+	HoldGain float64
+}
+
+// LargeMotorModel approximates an EV3 large motor.
+var LargeMotorModel = SimMotorModel{
+	MaxSpeed:          1050,
+	TimeConstant:      80 * time.Millisecond,
+	BrakeTimeConstant: 30 * time.Millisecond,
+	Friction:          2000,
+	StaticDuty:        0.05,
+	HoldGain:          20,
+}
+
+// MediumMotorModel approximates an EV3 medium motor.
+var MediumMotorModel = SimMotorModel{
+	MaxSpeed:          1560,
+	TimeConstant:      40 * time.Millisecond,
+	BrakeTimeConstant: 15 * time.Millisecond,
+	Friction:          3000,
+	StaticDuty:        0.04,
+	HoldGain:          20,
+}
+
+const (
+	simMotorStep      = time.Millisecond
+	simMotorStallTime = 200 * time.Millisecond
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Simulated Motor                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+var _ ev3lib.MotorInterface = &SimMotor{}
+
+// SimMotor is a simulated motor which advances with the ev3lib clock.
+// The motor is updated lazily whenever it is accessed.
+type SimMotor struct {
+	name  string
+	model SimMotorModel
+
+	inverted   bool
+	scale      float64
+	stopAction ev3lib.MotorStopAction
+
+	// Physical state of the shaft, unaffected by polarity
+	angle, speed float64
+	duty         float64
+
+	// Tacho count, affected by polarity and reset
+	count float64
+
+	running    bool
+	holding    bool
+	holdTarget float64
+
+	blocked   bool
+	load      float64
+	stallTime time.Duration
+
+	lastUpdate time.Time
+
+	m sync.Mutex
+}
+
+// NewSimMotor creates a simulated motor using the provided model.
+func NewSimMotor(name string, model SimMotorModel) *SimMotor {
+	return &SimMotor{name: name, model: model, scale: 1, stopAction: ev3lib.Coast}
+}
+
+// NewSimLargeMotor creates a simulated EV3 large motor.
+func NewSimLargeMotor(name string) *ev3lib.Motor {
+	return ev3lib.NewMotorBase(NewSimMotor(name, LargeMotorModel))
+}
+
+// NewSimMediumMotor creates a simulated EV3 medium motor.
+func NewSimMediumMotor(name string) *ev3lib.Motor {
+	return ev3lib.NewMotorBase(NewSimMotor(name, MediumMotorModel))
+}
+
+func (m *SimMotor) Name() string {
+	return m.name
+}
+
+// Angle returns the physical angle of the shaft in degrees, ignoring polarity, scale and resets.
+func (m *SimMotor) Angle() float64 {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.update()
+	return m.angle
+}
+
+// SetBlocked simulates the shaft being held still by an obstacle.
+func (m *SimMotor) SetBlocked(blocked bool) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.update()
+	m.blocked = blocked
+	if blocked {
+		m.speed = 0
+	}
+}
+
+// SetLoad simulates an external load from 0 (none) to 1 (stalled), reducing the motor's top speed.
+func (m *SimMotor) SetLoad(load float64) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.update()
+	m.load = ev3lib.Clamp(load, 0, 1)
+}
+
+func (m *SimMotor) CountPerRot() int {
+	return 360
+}
+
+func (m *SimMotor) State() ev3lib.MotorState {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.update()
+
+	var state ev3lib.MotorState
+	if m.running {
+		state |= ev3lib.Running
+	}
+	if m.holding {
+		state |= ev3lib.Holding
+	}
+	if m.stallTime >= simMotorStallTime {
+		state |= ev3lib.Stalled
+	}
+	return state
+}
+
+func (m *SimMotor) Inverted() bool {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	return m.inverted
+}
+
+func (m *SimMotor) SetInverted(inverted bool) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.update()
+	m.inverted = inverted
+}
+
+func (m *SimMotor) Scale() float64 {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	return m.scale
+}
+
+func (m *SimMotor) SetScale(scale float64) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.scale = scale
+}
+
+func (m *SimMotor) Position() float64 {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.update()
+	return math.Round(m.count) * m.scale
+}
+
+func (m *SimMotor) ResetPosition(pos float64) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.update()
+	m.count = pos / m.scale
+}
+
+func (m *SimMotor) Speed() float64 {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.update()
+	return m.polarity() * m.speed * m.scale
+}
+
+func (m *SimMotor) Set(power float64) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.update()
+	m.duty = m.polarity() * ev3lib.Clamp(power, -1, 1)
+	m.running = true
+	m.holding = false
+}
+
+func (m *SimMotor) Stop() {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.update()
+	m.duty = 0
+	m.running = false
+	m.holding = m.stopAction == ev3lib.Hold
+	m.holdTarget = m.angle
+}
+
+func (m *SimMotor) StopAction() ev3lib.MotorStopAction {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	return m.stopAction
+}
+
+func (m *SimMotor) SetStopAction(s ev3lib.MotorStopAction) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.update()
+	m.stopAction = s
+}
+
+func (m *SimMotor) polarity() float64 {
+	if m.inverted {
+		return -1
+	}
+	return 1
+}
+
+// update advances the simulation to the current time in small fixed steps.
+func (m *SimMotor) update() {
+	now := ev3lib.Now()
+	if m.lastUpdate.IsZero() || now.Before(m.lastUpdate) {
+		m.lastUpdate = now
+		return
+	}
+
+	remaining := now.Sub(m.lastUpdate)
+	m.lastUpdate = now
+
+	for remaining > 0 {
+		step := min(remaining, simMotorStep)
+		remaining -= step
+
+		m.step(step)
+	}
+}
+
+func (m *SimMotor) step(step time.Duration) {
+	dt := step.Seconds()
+
+	var target float64
+	switch {
+	case m.running:
+		target = m.targetSpeed()
+		m.speed += (target - m.speed) * (1 - math.Exp(-dt/m.model.TimeConstant.Seconds()))
+	case m.holding:
+		target = ev3lib.Clamp((m.holdTarget-m.angle)*m.model.HoldGain, -m.model.MaxSpeed, m.model.MaxSpeed)
+		m.speed += (target - m.speed) * (1 - math.Exp(-dt/m.model.BrakeTimeConstant.Seconds()))
+	case m.stopAction == ev3lib.Brake:
+		m.speed *= math.Exp(-dt / m.model.BrakeTimeConstant.Seconds())
+		m.speed = decelerate(m.speed, m.model.Friction*dt)
+	default:
+		m.speed = decelerate(m.speed, m.model.Friction*dt)
+	}
+
+	if m.blocked {
+		m.speed = 0
+	}
+
+	m.angle += m.speed * dt
+	m.count += m.polarity() * m.speed * dt
+
+	// Stalled when driven hard but barely turning
+	if m.running && math.Abs(target) > 0.2*m.model.MaxSpeed && math.Abs(m.speed) < 0.05*m.model.MaxSpeed {
+		m.stallTime += step
+	} else {
+		m.stallTime = 0
+	}
+}
+
+// targetSpeed returns the steady state speed for the current duty cycle and load.
+func (m *SimMotor) targetSpeed() float64 {
+	d := math.Abs(m.duty)
+	if d < m.model.StaticDuty {
+		return 0
+	}
+
+	speed := (d - m.model.StaticDuty) / (1 - m.model.StaticDuty) * m.model.MaxSpeed * (1 - m.load)
+	return math.Copysign(speed, m.duty)
+}
+
+// decelerate reduces the magnitude of speed by amount without crossing zero.
+func decelerate(speed, amount float64) float64 {
+	if math.Abs(speed) <= amount {
+		return 0
+	}
+	return speed - math.Copysign(amount, speed)
+}
@@ -0,0 +1,158 @@
+package testUtils
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+func TestSimMotorRun(t *testing.T) {
+	c := useTestClock(t)
+
+	m := NewSimMotor("test", LargeMotorModel)
+	m.Set(1)
+	c.Step(time.Second)
+
+	if got := m.Speed(); math.Abs(got-LargeMotorModel.MaxSpeed) > 1 {
+		t.Errorf("speed = %v, want %v", got, LargeMotorModel.MaxSpeed)
+	}
+	if got := m.Position(); got < 900 || got > 1050 {
+		t.Errorf("position = %v after 1s, want between 900 and 1050", got)
+	}
+	if got := m.State(); got != ev3lib.Running {
+		t.Errorf("state = %v, want running", got)
+	}
+
+	// Below the static friction duty cycle the motor does not turn
+	m = NewSimMotor("test", LargeMotorModel)
+	m.Set(LargeMotorModel.StaticDuty / 2)
+	c.Step(time.Second)
+	if got := m.Position(); got != 0 {
+		t.Errorf("position = %v under static friction, want 0", got)
+	}
+}
+
+func TestSimMotorInvertedAndScale(t *testing.T) {
+	c := useTestClock(t)
+
+	m := NewSimMotor("test", LargeMotorModel)
+	m.SetInverted(true)
+	m.SetScale(0.5)
+	m.Set(0.5)
+	c.Step(time.Second)
+
+	if !m.Inverted() || m.Scale() != 0.5 {
+		t.Fatal("inversion or scale not stored")
+	}
+
+	// The shaft turns backwards, while the tacho count goes forwards
+	angle, pos := m.Angle(), m.Position()
+	if angle >= 0 {
+		t.Errorf("angle = %v, want negative", angle)
+	}
+	if math.Abs(pos-(-angle*0.5)) > 1 {
+		t.Errorf("position = %v, want half the angle turned, %v", pos, -angle*0.5)
+	}
+	if m.Speed() <= 0 {
+		t.Errorf("speed = %v, want positive", m.Speed())
+	}
+
+	m.ResetPosition(10)
+	if got := m.Position(); got != 10 {
+		t.Errorf("position = %v after reset, want 10", got)
+	}
+}
+
+func TestSimMotorStopActions(t *testing.T) {
+	c := useTestClock(t)
+
+	travel := map[ev3lib.MotorStopAction]float64{}
+	for _, action := range []ev3lib.MotorStopAction{ev3lib.Coast, ev3lib.Brake, ev3lib.Hold} {
+		m := NewSimMotor("test", LargeMotorModel)
+		m.SetStopAction(action)
+		m.Set(1)
+		c.Step(time.Second)
+
+		m.Stop()
+		start := m.Angle()
+		c.Step(time.Second)
+
+		travel[action] = m.Angle() - start
+		if got := m.Speed(); math.Abs(got) > 1 {
+			t.Errorf("%v: speed = %v a second after stopping, want 0", action, got)
+		}
+		if got, holding := m.State(), action == ev3lib.Hold; got&ev3lib.Running != 0 || (got&ev3lib.Holding != 0) != holding {
+			t.Errorf("%v: state = %v after stopping", action, got)
+		}
+	}
+
+	// Coasting runs on furthest, braking stops quickly and holding returns to where it stopped
+	if travel[ev3lib.Coast] < 200 {
+		t.Errorf("coasted %v degrees, want over 200", travel[ev3lib.Coast])
+	}
+	if travel[ev3lib.Brake] <= 0 || travel[ev3lib.Brake] > 50 {
+		t.Errorf("braked over %v degrees, want between 0 and 50", travel[ev3lib.Brake])
+	}
+	if math.Abs(travel[ev3lib.Hold]) > 2 {
+		t.Errorf("held %v degrees from the stop, want within 2", travel[ev3lib.Hold])
+	}
+}
+
+func TestSimMotorStall(t *testing.T) {
+	c := useTestClock(t)
+
+	m := NewSimMotor("test", LargeMotorModel)
+	m.SetBlocked(true)
+	m.Set(1)
+
+	c.Step(simMotorStallTime / 2)
+	if m.State()&ev3lib.Stalled != 0 {
+		t.Error("stalled before the stall time")
+	}
+
+	c.Step(simMotorStallTime)
+	if got := m.State(); got != ev3lib.Running|ev3lib.Stalled {
+		t.Errorf("state = %v while blocked, want running and stalled", got)
+	}
+	if got := m.Position(); got != 0 {
+		t.Errorf("position = %v while blocked, want 0", got)
+	}
+
+	m.SetBlocked(false)
+	c.Step(100 * time.Millisecond)
+	if m.State()&ev3lib.Stalled != 0 {
+		t.Error("still stalled after unblocking")
+	}
+}
+
+func TestSimMotorConcurrent(t *testing.T) {
+	c := useTestClock(t)
+	m := NewSimMotor("test", LargeMotorModel)
+	m.Set(0.5)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			m.SetScale(float64(i%2) + 1)
+			m.SetStopAction(ev3lib.Brake)
+			m.SetInverted(i%2 == 0)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			c.Step(time.Millisecond)
+			m.Position()
+			m.Speed()
+			m.Scale()
+			m.StopAction()
+			m.Inverted()
+		}
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,405 @@
+package testUtils
+
+import (
+	"image"
+	_ "image/png"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Simulated World                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// SimRobotConfig describes the drive base of a simulated robot.
+// All distances are in millimetres.
+type SimRobotConfig struct {
+	WheelDiameter float64
+	WheelBase     float64
+
+	LeftModel, RightModel SimMotorModel
+}
+
+// SimWall is a wall segment that distance sensors can detect, in millimetres.
+type SimWall struct {
+	X1, Y1, X2, Y2 float64
+}
+
+// SimWorld simulates a differential drive robot on a field mat.
+// Positions are in millimetres from the bottom left of the mat, headings are in degrees counterclockwise from the x axis.
+// The world is updated lazily whenever one of its sensors is read.
+type SimWorld struct {
+	config SimRobotConfig
+
+	left, right         *SimMotor
+	lastLeft, lastRight float64
+
+	x, y, heading float64
+	rate          float64
+
+	mat                 image.Image
+	matWidth, matHeight float64
+
+	walls []SimWall
+
+	lastUpdate time.Time
+
+	m sync.Mutex
+}
+
+// NewSimWorld creates a world containing a robot at the origin facing along the x axis.
+func NewSimWorld(config SimRobotConfig) *SimWorld {
+	return &SimWorld{
+		config: config,
+		left:   NewSimMotor("Left Drive", config.LeftModel),
+		right:  NewSimMotor("Right Drive", config.RightModel),
+		walls:  make([]SimWall, 0),
+	}
+}
+
+// LeftMotor returns the simulated left drive motor. Positive power drives the robot forwards.
+func (w *SimWorld) LeftMotor() *ev3lib.Motor {
+	return ev3lib.NewMotorBase(w.left)
+}
+
+// RightMotor returns the simulated right drive motor. Positive power drives the robot forwards.
+func (w *SimWorld) RightMotor() *ev3lib.Motor {
+	return ev3lib.NewMotorBase(w.right)
+}
+
+// LoadMat loads a field mat from a PNG file, stretched to the provided size in millimetres.
+func (w *SimWorld) LoadMat(path string, width, height float64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return err
+	}
+
+	w.SetMat(img, width, height)
+	return nil
+}
+
+// SetMat sets the field mat image, stretched to the provided size in millimetres.
+func (w *SimWorld) SetMat(img image.Image, width, height float64) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.mat = img
+	w.matWidth = width
+	w.matHeight = height
+}
+
+// AddWall adds a wall segment between two points.
+func (w *SimWorld) AddWall(x1, y1, x2, y2 float64) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.walls = append(w.walls, SimWall{x1, y1, x2, y2})
+}
+
+// AddBorder adds walls around the edges of a field of the provided size.
+func (w *SimWorld) AddBorder(width, height float64) {
+	w.AddWall(0, 0, width, 0)
+	w.AddWall(width, 0, width, height)
+	w.AddWall(width, height, 0, height)
+	w.AddWall(0, height, 0, 0)
+}
+
+// SetPose moves the robot to a new position and heading.
+func (w *SimWorld) SetPose(x, y, heading float64) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.update()
+	w.x, w.y, w.heading = x, y, heading
+}
+
+// Pose returns the current position and heading of the robot.
+func (w *SimWorld) Pose() (x, y, heading float64) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.update()
+	return w.x, w.y, w.heading
+}
+
+// Update advances the robot to the current time.
+func (w *SimWorld) Update() {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.update()
+}
+
+func (w *SimWorld) update() {
+	now := ev3lib.Now()
+
+	l, r := w.left.Angle(), w.right.Angle()
+	if w.lastUpdate.IsZero() {
+		w.lastLeft, w.lastRight = l, r
+		w.lastUpdate = now
+		return
+	}
+
+	dl := (l - w.lastLeft) / 360 * math.Pi * w.config.WheelDiameter
+	dr := (r - w.lastRight) / 360 * math.Pi * w.config.WheelDiameter
+	w.lastLeft, w.lastRight = l, r
+
+	distance := (dl + dr) / 2
+	dHeading := (dr - dl) / w.config.WheelBase
+
+	mid := w.heading*math.Pi/180 + dHeading/2
+	w.x += distance * math.Cos(mid)
+	w.y += distance * math.Sin(mid)
+	w.heading += dHeading * 180 / math.Pi
+
+	if dt := now.Sub(w.lastUpdate).Seconds(); dt > 0 {
+		w.rate = dHeading * 180 / math.Pi / dt
+	}
+	w.lastUpdate = now
+}
+
+// toWorld converts an offset in the robot's frame to world coordinates.
+func (w *SimWorld) toWorld(forward, left float64) (x, y float64) {
+	h := w.heading * math.Pi / 180
+	return w.x + forward*math.Cos(h) - left*math.Sin(h), w.y + forward*math.Sin(h) + left*math.Cos(h)
+}
+
+// sampleMat returns the color of the mat at a point from 0 to 1.
+// Without a mat the field is white, and off the edge of the mat it is black.
+func (w *SimWorld) sampleMat(x, y float64) (r, g, b float64) {
+	if w.mat == nil {
+		return 1, 1, 1
+	}
+
+	if x < 0 || y < 0 || x >= w.matWidth || y >= w.matHeight {
+		return 0, 0, 0
+	}
+
+	bounds := w.mat.Bounds()
+	px := bounds.Min.X + int(x/w.matWidth*float64(bounds.Dx()))
+	py := bounds.Min.Y + int((1-y/w.matHeight)*float64(bounds.Dy()))
+
+	cr, cg, cb, _ := w.mat.At(px, py).RGBA()
+	return float64(cr) / 0xffff, float64(cg) / 0xffff, float64(cb) / 0xffff
+}
+
+// castRay returns the distance to the nearest wall from a point in a direction, or -1 if there is none.
+func (w *SimWorld) castRay(x, y, heading float64) float64 {
+	dx, dy := math.Cos(heading*math.Pi/180), math.Sin(heading*math.Pi/180)
+
+	nearest := -1.0
+	for _, wall := range w.walls {
+		ex, ey := wall.X2-wall.X1, wall.Y2-wall.Y1
+
+		denom := dx*ey - dy*ex
+		if math.Abs(denom) < 1e-9 {
+			continue
+		}
+
+		// Distance along the ray and fraction along the wall
+		t := ((wall.X1-x)*ey - (wall.Y1-y)*ex) / denom
+		u := ((wall.X1-x)*dy - (wall.Y1-y)*dx) / denom
+
+		if t >= 0 && u >= 0 && u <= 1 && (nearest < 0 || t < nearest) {
+			nearest = t
+		}
+	}
+
+	return nearest
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Simulated Color Sensor                                                     //
+////////////////////////////////////////////////////////////////////////////////
+
+var _ ev3lib.ColorSensorInterface = &simColorSensor{}
+var _ ev3lib.ColorDetectorInterface = &simColorSensor{}
+
+type simColorSensor struct {
+	w *SimWorld
+
+	forward, left float64
+}
+
+// NewColorSensor creates a color sensor mounted at an offset from the centre of the drive wheels.
+func (w *SimWorld) NewColorSensor(forward, left float64) *ev3lib.ColorSensor {
+	return ev3lib.NewColorSensorBase(&simColorSensor{w: w, forward: forward, left: left})
+}
+
+func (s *simColorSensor) sample() (float64, float64, float64) {
+	s.w.m.Lock()
+	defer s.w.m.Unlock()
+
+	s.w.update()
+	return s.w.sampleMat(s.w.toWorld(s.forward, s.left))
+}
+
+func (s *simColorSensor) Ambient() float64 {
+	return 0
+}
+
+func (s *simColorSensor) Reflection() float64 {
+	r, g, b := s.sample()
+	return 0.299*r + 0.587*g + 0.114*b
+}
+
+func (s *simColorSensor) GetRGB() (float64, float64, float64) {
+	return s.sample()
+}
+
+// Color returns the LEGO color nearest to the mat under the sensor.
+func (s *simColorSensor) Color() ev3lib.LegoColor {
+	color, _ := ev3lib.ClassifyLegoColor(s.sample())
+	return color
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Simulated Gyro Sensor                                                      //
+////////////////////////////////////////////////////////////////////////////////
+
+var _ ev3lib.GyroSensorInterface = &simGyroSensor{}
+
+type simGyroSensor struct {
+	w *SimWorld
+
+	offset float64
+
+	drift   float64
+	created time.Time
+}
+
+// NewGyroSensor creates a gyro which follows the heading of the robot.
+// Like the EV3 gyro, angles increase when turning clockwise.
+func (w *SimWorld) NewGyroSensor() *ev3lib.GyroSensor {
+	_, _, heading := w.Pose()
+	return ev3lib.NewGyroSensorBase(&simGyroSensor{w: w, offset: heading})
+}
+
+// NewDriftingGyroSensor creates a gyro whose angle drifts by a rate in degrees per second, like an EV3 gyro moved while powering on.
+func (w *SimWorld) NewDriftingGyroSensor(drift float64) *ev3lib.GyroSensor {
+	_, _, heading := w.Pose()
+	return ev3lib.NewGyroSensorBase(&simGyroSensor{w: w, offset: heading, drift: drift, created: ev3lib.Now()})
+}
+
+func (s *simGyroSensor) driftAngle() float64 {
+	if s.drift == 0 {
+		return 0
+	}
+	return s.drift * ev3lib.Since(s.created).Seconds()
+}
+
+func (s *simGyroSensor) Rate() float64 {
+	_, rate := s.AngleRate()
+	return rate
+}
+
+func (s *simGyroSensor) Angle() float64 {
+	angle, _ := s.AngleRate()
+	return angle
+}
+
+func (s *simGyroSensor) AngleRate() (float64, float64) {
+	s.w.m.Lock()
+	defer s.w.m.Unlock()
+
+	s.w.update()
+	return math.Round(s.offset - s.w.heading + s.driftAngle()), math.Round(-s.w.rate + s.drift)
+}
+
+func (s *simGyroSensor) ResetAngle(angle float64) {
+	s.w.m.Lock()
+	defer s.w.m.Unlock()
+
+	s.w.update()
+	s.offset = s.w.heading + angle - s.driftAngle()
+}
+
+func (s *simGyroSensor) Calibrate() {}
+
+////////////////////////////////////////////////////////////////////////////////
+// Simulated Ultrasonic Sensor                                                //
+////////////////////////////////////////////////////////////////////////////////
+
+var _ ev3lib.UltrasonicSensorInterface = &simUltrasonicSensor{}
+
+type simUltrasonicSensor struct {
+	w *SimWorld
+
+	forward, left, angle float64
+}
+
+// NewUltrasonicSensor creates an ultrasonic sensor mounted at an offset and angle from the robot's heading.
+// Distances match the EV3 driver, from 0 to 2550 with 2550 meaning nothing was detected.
+func (w *SimWorld) NewUltrasonicSensor(forward, left, angle float64) *ev3lib.UltrasonicSensor {
+	return ev3lib.NewUltrasonicSensorBase(&simUltrasonicSensor{w: w, forward: forward, left: left, angle: angle})
+}
+
+func (s *simUltrasonicSensor) Distance() float64 {
+	s.w.m.Lock()
+	defer s.w.m.Unlock()
+
+	s.w.update()
+	x, y := s.w.toWorld(s.forward, s.left)
+
+	d := s.w.castRay(x, y, s.w.heading+s.angle)
+	if d < 0 || d > 2550 {
+		return 2550
+	}
+	return math.Round(d)
+}
+
+func (s *simUltrasonicSensor) DistanceSilent() float64 {
+	return s.Distance()
+}
+
+func (s *simUltrasonicSensor) Presence() bool {
+	return false
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Simulated Infrared Sensor                                                  //
+////////////////////////////////////////////////////////////////////////////////
+
+// simInfraredRange is the distance in millimetres at which the infrared sensor reads 1.
+const simInfraredRange = 700
+
+var _ ev3lib.InfraredSensorInterface = &simInfraredSensor{}
+
+type simInfraredSensor struct {
+	w *SimWorld
+
+	forward, left, angle float64
+}
+
+// NewInfraredSensor creates an infrared sensor mounted at an offset and angle from the robot's heading.
+func (w *SimWorld) NewInfraredSensor(forward, left, angle float64) *ev3lib.InfraredSensor {
+	return ev3lib.NewInfraredSensorBase(&simInfraredSensor{w: w, forward: forward, left: left, angle: angle})
+}
+
+func (s *simInfraredSensor) Distance() float64 {
+	s.w.m.Lock()
+	defer s.w.m.Unlock()
+
+	s.w.update()
+	x, y := s.w.toWorld(s.forward, s.left)
+
+	d := s.w.castRay(x, y, s.w.heading+s.angle)
+	if d < 0 {
+		return 1
+	}
+	return math.Round(min(d/simInfraredRange, 1)*100) / 100
+}
+
+func (s *simInfraredSensor) Buttons(channel int) []ev3lib.BeaconButton {
+	return []ev3lib.BeaconButton{}
+}
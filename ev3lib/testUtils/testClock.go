@@ -0,0 +1,72 @@
+package testUtils
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Manual Clock                                                               //
+////////////////////////////////////////////////////////////////////////////////
+
+var _ ev3lib.Clock = &ManualClock{}
+
+// ManualClock is a clock which only moves forward when stepped or slept on.
+// Sleeping advances the clock instantly, so timed runners finish as fast as the CPU allows.
+type ManualClock struct {
+	now time.Time
+
+	m sync.Mutex
+}
+
+// NewManualClock creates a manual clock starting at the unix epoch.
+func NewManualClock() *ManualClock {
+	return &ManualClock{now: time.Unix(0, 0)}
+}
+
+// UseManualClock creates a manual clock and sets it as the clock used by ev3lib.
+func UseManualClock() *ManualClock {
+	c := NewManualClock()
+	ev3lib.SetClock(c)
+	return c
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	return c.now
+}
+
+// Sleep advances the clock by `dur` without blocking.
+func (c *ManualClock) Sleep(dur time.Duration) {
+	c.Step(dur)
+}
+
+// Step advances the clock by `dur`.
+func (c *ManualClock) Step(dur time.Duration) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.now = c.now.Add(dur)
+}
+
+// Elapsed returns the time passed since the clock started.
+func (c *ManualClock) Elapsed() time.Duration {
+	return c.Now().Sub(time.Unix(0, 0))
+}
+
+// StepCommand runs a single frame of a command, then advances the clock by `frame`.
+// The command must already be initialised. Returns true once the command is done, at which point it has been ended.
+func (c *ManualClock) StepCommand(command ev3lib.CommandInterface, frame time.Duration) bool {
+	command.Run()
+	c.Step(frame)
+
+	if command.IsDone() {
+		command.End(false)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package testUtils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+func useTestClock(t *testing.T) *ManualClock {
+	prev := ev3lib.GetClock()
+	t.Cleanup(func() { ev3lib.SetClock(prev) })
+
+	return UseManualClock()
+}
+
+func TestManualClockTimedCommand(t *testing.T) {
+	c := useTestClock(t)
+
+	start := time.Now()
+	ev3lib.RunTimedCommand(ev3lib.NewSequence(
+		ev3lib.NewWaitCommand(10*time.Second),
+		ev3lib.NewWaitCommand(5*time.Second),
+	), 20*time.Millisecond)
+	took := time.Since(start)
+
+	if c.Elapsed() < 15*time.Second {
+		t.Errorf("clock elapsed %v, want at least 15s", c.Elapsed())
+	}
+	if took > time.Second {
+		t.Errorf("took %v of real time, want milliseconds", took)
+	}
+}
+
+func TestManualClockStepCommand(t *testing.T) {
+	c := useTestClock(t)
+
+	cmd := ev3lib.NewWaitCommand(time.Second)
+	cmd.Init()
+
+	frames := 0
+	for !c.StepCommand(cmd, 100*time.Millisecond) {
+		frames++
+		if frames > 100 {
+			t.Fatal("wait command never finished")
+		}
+	}
+
+	if frames != 10 {
+		t.Errorf("finished after %d frames, want 10", frames)
+	}
+}
@@ -1,7 +1,11 @@
 package testUtils
 
 import (
+	"fmt"
+	"io"
 	"log"
+	"os"
+	"time"
 
 	"github.com/Alanlu217/ev3lib/ev3lib"
 )
@@ -12,10 +16,18 @@ import (
 
 var _ ev3lib.EV3BrickInterface = &testEV3Brick{}
 
-type testEV3Brick struct{}
+type testEV3Brick struct {
+	sound *soundRecorder
+}
 
 func NewTestEV3Brick() *ev3lib.EV3Brick {
-	return ev3lib.NewEV3BrickBase(&testEV3Brick{})
+	return NewRecordingTestEV3Brick("")
+}
+
+// NewRecordingTestEV3Brick creates a test brick which records its sounds to a WAV file, so they can be checked off the brick.
+// The recording follows the current clock and is saved after every sound. An empty path records nothing.
+func NewRecordingTestEV3Brick(path string) *ev3lib.EV3Brick {
+	return ev3lib.NewEV3BrickBase(&testEV3Brick{sound: newSoundRecorder(path)})
 }
 
 func (testEV3Brick) IsButtonPressed(button ev3lib.EV3Button) bool {
@@ -40,23 +52,85 @@ func (*testEV3Brick) ButtonsPressed() []ev3lib.EV3Button {
 
 func (*testEV3Brick) SetLight(color ev3lib.EV3Color) {}
 
-func (*testEV3Brick) Beep(frequency float64, duration float64) {
-	log.Printf("beep at %v for %v\n", frequency, duration)
+func (*testEV3Brick) SetLED(led ev3lib.EV3LED, color ev3lib.EV3Color) {}
+
+func (*testEV3Brick) PlayLightPattern(pattern ev3lib.LightPattern) {}
+
+func (t *testEV3Brick) Beep(frequency float64, duration float64) {
+	t.sound.record(func(volume float64) []int16 {
+		return renderTone(frequency, time.Duration(duration*float64(time.Second)), volume)
+	})
+}
+
+func (t *testEV3Brick) PlayNotes(notes []ev3lib.EV3Note, tempo float64) {
+	parsed, err := ev3lib.ParseNotes(notes)
+	if err != nil {
+		log.Printf("could not play notes: %v\n", err)
+		return
+	}
+
+	t.sound.record(func(volume float64) []int16 {
+		return renderNotes(parsed, tempo, volume)
+	})
+}
+
+func (t *testEV3Brick) SpeakText(text string) {
+	wav, err := ev3lib.GetSpeechBackend().Synthesize(text)
+	if err != nil {
+		log.Printf("could not speak %q: %v\n", text, err)
+		return
+	}
+
+	t.sound.record(func(volume float64) []int16 {
+		return renderWAV(wav, volume)
+	})
 }
 
-func (*testEV3Brick) PlayNotes(notes []ev3lib.EV3Note, tempo float64) {
-	log.Printf("play %v at %v bpm\n", notes, tempo)
+func (t *testEV3Brick) PlayWAV(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Printf("could not play %v: %v\n", path, err)
+		return
+	}
+	defer f.Close()
+
+	wav, err := ev3lib.ReadWAV(f)
+	if err != nil {
+		log.Printf("could not play %v: %v\n", path, err)
+		return
+	}
+
+	t.sound.record(func(volume float64) []int16 {
+		return renderWAV(wav, volume)
+	})
 }
 
-func (*testEV3Brick) SetVolume(volume float64) {
-	log.Printf("set volume to %v\n", volume)
+func (t *testEV3Brick) StopSound() {
+	t.sound.stop()
+}
+
+func (t *testEV3Brick) IsSoundPlaying() bool {
+	return t.sound.isPlaying()
+}
+
+func (t *testEV3Brick) SetVolume(volume float64) {
+	t.sound.setVolume(volume)
 }
 
 func (*testEV3Brick) ClearScreen() {}
 
 func (*testEV3Brick) DrawText(x int, y int, text string) {}
 
-func (*testEV3Brick) PrintScreen(text ...string) {}
+// PrintScreen prints each string on its own line of stdout.
+func (*testEV3Brick) PrintScreen(text ...string) {
+	for _, line := range text {
+		fmt.Println(line)
+	}
+}
+
+func (*testEV3Brick) Console() io.Writer {
+	return os.Stdout
+}
 
 func (*testEV3Brick) DrawPixel(x int, y int, black bool) {}
 
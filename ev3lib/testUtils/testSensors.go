@@ -7,6 +7,7 @@ import "github.com/Alanlu217/ev3lib/ev3lib"
 ////////////////////////////////////////////////////////////////////////////////
 
 var _ ev3lib.ColorSensorInterface = &testColorSensor{}
+var _ ev3lib.ColorDetectorInterface = &testColorSensor{}
 
 type testColorSensor struct{}
 
@@ -26,6 +27,10 @@ func (s *testColorSensor) GetRGB() (float64, float64, float64) {
 	return 0, 0, 0
 }
 
+func (s *testColorSensor) Color() ev3lib.LegoColor {
+	return ev3lib.NoColor
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Test Gyro Sensor                                                           //
 ////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,153 @@
+package testUtils
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Sound Recorder                                                             //
+////////////////////////////////////////////////////////////////////////////////
+
+// SoundSampleRate is the sample rate sounds are recorded at.
+const SoundSampleRate = 22050
+
+// soundRecorder renders sounds onto a timeline following the current clock, so recordings match simulated time.
+// The timeline starts at the first sound.
+type soundRecorder struct {
+	m sync.Mutex
+
+	path    string
+	volume  float64
+	start   time.Time
+	end     time.Time
+	samples []int16
+}
+
+func newSoundRecorder(path string) *soundRecorder {
+	return &soundRecorder{path: path, volume: 1}
+}
+
+func (r *soundRecorder) index(t time.Time) int {
+	return max(0, int(t.Sub(r.start).Seconds()*SoundSampleRate))
+}
+
+// cut ends the recording at an index, padding it with silence if it is shorter.
+func (r *soundRecorder) cut(i int) {
+	if len(r.samples) > i {
+		r.samples = r.samples[:i]
+	} else {
+		r.samples = append(r.samples, make([]int16, i-len(r.samples))...)
+	}
+}
+
+// record adds a sound at the current time, replacing the rest of the sound playing.
+func (r *soundRecorder) record(render func(volume float64) []int16) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	now := ev3lib.Now()
+	if r.start.IsZero() {
+		r.start = now
+	}
+
+	sound := render(r.volume)
+	r.cut(r.index(now))
+	r.samples = append(r.samples, sound...)
+	r.end = now.Add(time.Duration(len(sound)) * time.Second / SoundSampleRate)
+
+	r.save()
+}
+
+func (r *soundRecorder) stop() {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	now := ev3lib.Now()
+	if !now.Before(r.end) {
+		return
+	}
+
+	r.cut(r.index(now))
+	r.end = now
+
+	r.save()
+}
+
+func (r *soundRecorder) isPlaying() bool {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	return ev3lib.Now().Before(r.end)
+}
+
+func (r *soundRecorder) setVolume(volume float64) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.volume = ev3lib.Clamp(volume, 0, 1)
+}
+
+// save rewrites the file with the whole recording, so it is complete whenever the program stops.
+func (r *soundRecorder) save() {
+	if r.path == "" {
+		return
+	}
+
+	f, err := os.Create(r.path)
+	if err != nil {
+		log.Printf("could not save sound: %v\n", err)
+		return
+	}
+	defer f.Close()
+
+	wav := ev3lib.WAV{SampleRate: SoundSampleRate, Channels: 1, Samples: r.samples}
+	if err := wav.Write(f); err != nil {
+		log.Printf("could not save sound: %v\n", err)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Rendering                                                                  //
+////////////////////////////////////////////////////////////////////////////////
+
+func samples(d time.Duration) int {
+	return int(d.Seconds() * SoundSampleRate)
+}
+
+// renderTone renders a square wave like the EV3 speaker.
+func renderTone(frequency float64, d time.Duration, volume float64) []int16 {
+	out := make([]int16, samples(d))
+	amplitude := int16(volume * 16000)
+
+	for i := range out {
+		if int(2*frequency*float64(i)/SoundSampleRate)%2 == 0 {
+			out[i] = amplitude
+		} else {
+			out[i] = -amplitude
+		}
+	}
+	return out
+}
+
+func renderNotes(notes []ev3lib.Note, tempo, volume float64) []int16 {
+	var out []int16
+	for _, note := range notes {
+		sound, gap := note.Timing(tempo)
+		out = append(out, renderTone(note.Frequency, sound, volume)...)
+		out = append(out, make([]int16, samples(gap))...)
+	}
+	return out
+}
+
+func renderWAV(wav *ev3lib.WAV, volume float64) []int16 {
+	out := wav.Mono(SoundSampleRate)
+	for i := range out {
+		out[i] = int16(float64(out[i]) * volume)
+	}
+	return out
+}
@@ -0,0 +1,328 @@
+package ev3lib
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Splines                                                                    //
+////////////////////////////////////////////////////////////////////////////////
+
+// SplineType selects the curve drawn between waypoints.
+type SplineType int
+
+const (
+	// QuinticSpline uses quintic Hermite splines, which have smoother curvature at the waypoints.
+	QuinticSpline SplineType = iota
+
+	// CubicSpline uses cubic Hermite splines.
+	CubicSpline
+)
+
+// splineSegment is a polynomial curve between two waypoints, for t from 0 to 1.
+type splineSegment struct {
+	x, y [6]float64
+}
+
+func newSplineSegment(spline SplineType, a, b Pose2d) splineSegment {
+	// Tangents point along the waypoint headings, scaled by the distance between them
+	scale := 1.2 * a.DistanceTo(b)
+	vax, vay := scale*math.Cos(a.HeadingRadians()), scale*math.Sin(a.HeadingRadians())
+	vbx, vby := scale*math.Cos(b.HeadingRadians()), scale*math.Sin(b.HeadingRadians())
+
+	coefficients := func(p0, v0, p1, v1 float64) [6]float64 {
+		if spline == CubicSpline {
+			return [6]float64{p0, v0, 3*(p1-p0) - 2*v0 - v1, 2*(p0-p1) + v0 + v1}
+		}
+		// Second derivatives are zero at the waypoints
+		return [6]float64{p0, v0, 0, 10*(p1-p0) - 6*v0 - 4*v1, -15*(p1-p0) + 8*v0 + 7*v1, 6*(p1-p0) - 3*v0 - 3*v1}
+	}
+
+	return splineSegment{x: coefficients(a.X, vax, b.X, vbx), y: coefficients(a.Y, vay, b.Y, vby)}
+}
+
+// evaluatePolynomial returns the value and first two derivatives of a polynomial.
+func evaluatePolynomial(c [6]float64, t float64) (p, d, dd float64) {
+	for i := len(c) - 1; i >= 0; i-- {
+		p = p*t + c[i]
+	}
+	for i := len(c) - 1; i >= 1; i-- {
+		d = d*t + float64(i)*c[i]
+	}
+	for i := len(c) - 1; i >= 2; i-- {
+		dd = dd*t + float64(i*(i-1))*c[i]
+	}
+	return p, d, dd
+}
+
+// sample returns the pose, with heading along the curve, and curvature at t.
+func (s splineSegment) sample(t float64) (Pose2d, float64) {
+	x, dx, ddx := evaluatePolynomial(s.x, t)
+	y, dy, ddy := evaluatePolynomial(s.y, t)
+
+	curvature := 0.0
+	if speed := math.Hypot(dx, dy); speed > 0 {
+		curvature = (dx*ddy - dy*ddx) / (speed * speed * speed)
+	}
+
+	return Pose2d{X: x, Y: y, Heading: math.Atan2(dy, dx) * 180 / math.Pi}, curvature
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Spline Trajectory                                                          //
+////////////////////////////////////////////////////////////////////////////////
+
+// TrajectoryConfig holds the constraints used to time a trajectory.
+// Units are those of the waypoints, e.g. millimetres per second.
+//
+// A max acceleration of 0 or less means the acceleration is unlimited, as in MotionProfile.
+// A max velocity of 0 or less means the robot can't move, so the trajectory has a duration of 0.
+type TrajectoryConfig struct {
+	MaxVelocity, MaxAcceleration float64
+
+	// MaxCentripetalAcceleration slows the robot in tight turns, 0 disables the limit.
+	MaxCentripetalAcceleration float64
+
+	StartVelocity, EndVelocity float64
+
+	// Reversed drives the trajectory backwards. Waypoint headings are still the direction the robot faces.
+	Reversed bool
+}
+
+// NewTrajectoryConfig returns a config starting and ending at rest with no centripetal limit.
+func NewTrajectoryConfig(maxVelocity, maxAcceleration float64) TrajectoryConfig {
+	return TrajectoryConfig{MaxVelocity: maxVelocity, MaxAcceleration: maxAcceleration}
+}
+
+// samplesPerSegment is the number of points each spline segment is split into when timing it.
+const samplesPerSegment = 200
+
+// SplineTrajectory is a timed trajectory through waypoints, which can be followed with DifferentialDrive.FollowTrajectory.
+type SplineTrajectory struct {
+	states []TrajectoryState
+}
+
+// GenerateTrajectory creates a trajectory through waypoints, leaving each in the direction of its heading.
+func GenerateTrajectory(waypoints []Pose2d, spline SplineType, config TrajectoryConfig) *SplineTrajectory {
+	if len(waypoints) == 0 {
+		return &SplineTrajectory{states: []TrajectoryState{{}}}
+	}
+
+	// Reversed paths are drawn in the direction of travel, opposite the robot's heading
+	points := make([]Pose2d, len(waypoints))
+	copy(points, waypoints)
+	if config.Reversed {
+		for i := range points {
+			points[i].Heading += 180
+		}
+	}
+
+	poses := []Pose2d{points[0]}
+	curvatures := []float64{0}
+	if len(points) > 1 {
+		_, curvatures[0] = newSplineSegment(spline, points[0], points[1]).sample(0)
+	}
+
+	for i := 0; i < len(points)-1; i++ {
+		seg := newSplineSegment(spline, points[i], points[i+1])
+		for j := 1; j <= samplesPerSegment; j++ {
+			pose, curvature := seg.sample(float64(j) / samplesPerSegment)
+			poses = append(poses, pose)
+			curvatures = append(curvatures, curvature)
+		}
+	}
+
+	// Keep headings continuous so they can be interpolated
+	for i := 1; i < len(poses); i++ {
+		poses[i].Heading = poses[i-1].Heading + math.Remainder(poses[i].Heading-poses[i-1].Heading, 360)
+	}
+
+	distances := make([]float64, len(poses))
+	for i := 1; i < len(poses); i++ {
+		distances[i] = poses[i-1].DistanceTo(poses[i])
+	}
+
+	maxVelocity := 0.0
+	if config.MaxVelocity > 0 {
+		maxVelocity = config.MaxVelocity
+	}
+
+	// Limit velocity by curvature, then by acceleration forwards from the start and backwards from the end
+	velocities := make([]float64, len(poses))
+	for i := range velocities {
+		velocities[i] = maxVelocity
+		if config.MaxCentripetalAcceleration > 0 && curvatures[i] != 0 {
+			velocities[i] = min(velocities[i], math.Sqrt(config.MaxCentripetalAcceleration/math.Abs(curvatures[i])))
+		}
+	}
+	velocities[0] = min(velocities[0], config.StartVelocity)
+	velocities[len(velocities)-1] = min(velocities[len(velocities)-1], config.EndVelocity)
+
+	if config.MaxAcceleration > 0 {
+		for i := 1; i < len(velocities); i++ {
+			velocities[i] = min(velocities[i], math.Sqrt(velocities[i-1]*velocities[i-1]+2*config.MaxAcceleration*distances[i]))
+		}
+		for i := len(velocities) - 2; i >= 0; i-- {
+			velocities[i] = min(velocities[i], math.Sqrt(velocities[i+1]*velocities[i+1]+2*config.MaxAcceleration*distances[i+1]))
+		}
+	}
+
+	sign := 1.0
+	if config.Reversed {
+		sign = -1
+	}
+
+	states := make([]TrajectoryState, len(poses))
+	t := 0.0
+	for i := range poses {
+		if i > 0 && velocities[i-1]+velocities[i] > 0 {
+			t += 2 * distances[i] / (velocities[i-1] + velocities[i])
+		}
+
+		acceleration := 0.0
+		if i < len(poses)-1 && distances[i+1] > 0 {
+			acceleration = (velocities[i+1]*velocities[i+1] - velocities[i]*velocities[i]) / (2 * distances[i+1])
+		}
+
+		pose := poses[i]
+		if config.Reversed {
+			pose.Heading -= 180
+		}
+
+		states[i] = TrajectoryState{
+			Time:         time.Duration(t * float64(time.Second)),
+			Pose:         pose,
+			Velocity:     sign * velocities[i],
+			Acceleration: sign * acceleration,
+			Curvature:    sign * curvatures[i],
+		}
+	}
+
+	return &SplineTrajectory{states: states}
+}
+
+// Duration returns the time taken to drive the trajectory.
+func (s *SplineTrajectory) Duration() time.Duration {
+	return s.states[len(s.states)-1].Time
+}
+
+// Sample returns the state at a time since the start of the trajectory, clamped to the ends.
+func (s *SplineTrajectory) Sample(t time.Duration) TrajectoryState {
+	if t <= 0 {
+		return s.states[0]
+	}
+	if t >= s.Duration() {
+		return s.states[len(s.states)-1]
+	}
+
+	i := sort.Search(len(s.states), func(i int) bool { return s.states[i].Time > t }) - 1
+	a, b := s.states[i], s.states[i+1]
+
+	// Accelerate at a constant rate between the two states
+	dt := (t - a.Time).Seconds()
+	state := TrajectoryState{
+		Time:         t,
+		Velocity:     a.Velocity + a.Acceleration*dt,
+		Acceleration: a.Acceleration,
+		Curvature:    a.Curvature,
+	}
+
+	frac := 0.0
+	if span := (b.Time - a.Time).Seconds(); span > 0 {
+		travelled := math.Abs(a.Velocity*dt + a.Acceleration*dt*dt/2)
+		if d := a.Pose.DistanceTo(b.Pose); d > 0 {
+			frac = Clamp(travelled/d, 0, 1)
+		} else {
+			frac = dt / span
+		}
+	}
+
+	state.Pose = Pose2d{
+		X:       a.Pose.X + (b.Pose.X-a.Pose.X)*frac,
+		Y:       a.Pose.Y + (b.Pose.Y-a.Pose.Y)*frac,
+		Heading: a.Pose.Heading + (b.Pose.Heading-a.Pose.Heading)*frac,
+	}
+	state.Curvature = a.Curvature + (b.Curvature-a.Curvature)*frac
+
+	return state
+}
+
+// States returns every state the trajectory was generated with.
+func (s *SplineTrajectory) States() []TrajectoryState {
+	return s.states
+}
+
+// Poses returns the poses along the trajectory, which can be followed with DifferentialDrive.PurePursuit.
+func (s *SplineTrajectory) Poses() []Pose2d {
+	poses := make([]Pose2d, len(s.states))
+	for i, state := range s.states {
+		poses[i] = state.Pose
+	}
+	return poses
+}
+
+// WriteCSV writes the states as CSV for plotting, with times in seconds.
+func (s *SplineTrajectory) WriteCSV(w io.Writer) error {
+	c := csv.NewWriter(w)
+
+	if err := c.Write([]string{"time", "x", "y", "heading", "velocity", "acceleration", "curvature"}); err != nil {
+		return err
+	}
+
+	format := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	for _, state := range s.states {
+		err := c.Write([]string{
+			format(state.Time.Seconds()),
+			format(state.Pose.X),
+			format(state.Pose.Y),
+			format(state.Pose.Heading),
+			format(state.Velocity),
+			format(state.Acceleration),
+			format(state.Curvature),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	c.Flush()
+	return c.Error()
+}
+
+type trajectoryStateJSON struct {
+	Time         float64 `json:"time"`
+	X            float64 `json:"x"`
+	Y            float64 `json:"y"`
+	Heading      float64 `json:"heading"`
+	Velocity     float64 `json:"velocity"`
+	Acceleration float64 `json:"acceleration"`
+	Curvature    float64 `json:"curvature"`
+}
+
+// WriteJSON writes the states as a JSON array for plotting, with times in seconds.
+func (s *SplineTrajectory) WriteJSON(w io.Writer) error {
+	states := make([]trajectoryStateJSON, len(s.states))
+	for i, state := range s.states {
+		states[i] = trajectoryStateJSON{
+			Time:         state.Time.Seconds(),
+			X:            state.Pose.X,
+			Y:            state.Pose.Y,
+			Heading:      state.Pose.Heading,
+			Velocity:     state.Velocity,
+			Acceleration: state.Acceleration,
+			Curvature:    state.Curvature,
+		}
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(states)
+}
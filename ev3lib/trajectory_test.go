@@ -0,0 +1,218 @@
+package ev3lib_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+func TestTrajectorySpline(t *testing.T) {
+	waypoints := []ev3lib.Pose2d{{}, {X: 500, Y: 200, Heading: 45}, {X: 800, Y: -100, Heading: -90}}
+
+	for _, spline := range []ev3lib.SplineType{ev3lib.QuinticSpline, ev3lib.CubicSpline} {
+		for _, reversed := range []bool{false, true} {
+			config := ev3lib.NewTrajectoryConfig(300, 600)
+			config.Reversed = reversed
+			states := ev3lib.GenerateTrajectory(waypoints, spline, config).States()
+
+			// Each segment is split into the same number of samples, so waypoints fall on every segment boundary
+			per := (len(states) - 1) / (len(waypoints) - 1)
+			for i, want := range waypoints {
+				got := states[i*per].Pose
+				if math.Hypot(got.X-want.X, got.Y-want.Y) > 1e-6 {
+					t.Errorf("spline %v reversed %v: waypoint %v at (%v, %v), want (%v, %v)", spline, reversed, i, got.X, got.Y, want.X, want.Y)
+				}
+				if e := math.Remainder(got.Heading-want.Heading, 360); math.Abs(e) > 1e-6 {
+					t.Errorf("spline %v reversed %v: waypoint %v heading = %v, want %v", spline, reversed, i, got.Heading, want.Heading)
+				}
+			}
+		}
+	}
+}
+
+func TestTrajectoryCurvature(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		end  ev3lib.Pose2d
+		turn float64
+	}{
+		{"straight", ev3lib.Pose2d{X: 500}, 0},
+		{"left", ev3lib.Pose2d{X: 300, Y: 300, Heading: 90}, math.Pi / 2},
+		{"right", ev3lib.Pose2d{X: 300, Y: -300, Heading: -90}, -math.Pi / 2},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			trajectory := ev3lib.GenerateTrajectory([]ev3lib.Pose2d{{}, test.end}, ev3lib.QuinticSpline, ev3lib.NewTrajectoryConfig(300, 600))
+			states := trajectory.States()
+
+			if mid := states[len(states)/2].Curvature; math.Signbit(mid) != math.Signbit(test.turn) || (test.turn == 0) != (math.Abs(mid) < 1e-9) {
+				t.Errorf("curvature = %v, want the sign of %v", mid, test.turn)
+			}
+
+			// Curvature matches the change in heading along the path
+			total := 0.0
+			for i := 1; i < len(states); i++ {
+				a, b := states[i-1], states[i]
+				turned := (b.Pose.Heading - a.Pose.Heading) * math.Pi / 180
+				expected := (a.Curvature + b.Curvature) / 2 * a.Pose.DistanceTo(b.Pose)
+				if math.Abs(turned-expected) > 1e-3 {
+					t.Fatalf("state %v turned %v rad, curvature gives %v", i, turned, expected)
+				}
+				total += expected
+			}
+			if math.Abs(total-test.turn) > 1e-3 {
+				t.Errorf("curvature turns %v rad along the path, want %v", total, test.turn)
+			}
+		})
+	}
+}
+
+func TestTrajectoryTiming(t *testing.T) {
+	waypoints := []ev3lib.Pose2d{{}, {X: 400, Y: 400, Heading: 90}}
+
+	for _, test := range []struct {
+		name   string
+		config func(c *ev3lib.TrajectoryConfig)
+
+		// unlimited skips the acceleration check, empty expects a duration of 0
+		unlimited, empty bool
+	}{
+		{"default", nil, false, false},
+		{"centripetal", func(c *ev3lib.TrajectoryConfig) { c.MaxCentripetalAcceleration = 100 }, false, false},
+		{"moving start and end", func(c *ev3lib.TrajectoryConfig) { c.StartVelocity, c.EndVelocity = 100, 200 }, false, false},
+		{"reversed", func(c *ev3lib.TrajectoryConfig) { c.Reversed = true }, false, false},
+		{"zero acceleration", func(c *ev3lib.TrajectoryConfig) { c.MaxAcceleration = 0 }, true, false},
+		{"negative acceleration", func(c *ev3lib.TrajectoryConfig) { c.MaxAcceleration = -1 }, true, false},
+		{"NaN acceleration", func(c *ev3lib.TrajectoryConfig) { c.MaxAcceleration = math.NaN() }, true, false},
+		{"zero velocity", func(c *ev3lib.TrajectoryConfig) { c.MaxVelocity = 0 }, false, true},
+		{"NaN velocity", func(c *ev3lib.TrajectoryConfig) { c.MaxVelocity = math.NaN() }, false, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := ev3lib.NewTrajectoryConfig(300, 600)
+			if test.config != nil {
+				test.config(&config)
+			}
+			trajectory := ev3lib.GenerateTrajectory(waypoints, ev3lib.QuinticSpline, config)
+			states := trajectory.States()
+
+			if test.empty {
+				if trajectory.Duration() != 0 {
+					t.Errorf("duration = %v, want 0", trajectory.Duration())
+				}
+				return
+			}
+			if trajectory.Duration() <= 0 {
+				t.Fatalf("duration = %v, want more than 0", trajectory.Duration())
+			}
+
+			sign := 1.0
+			if config.Reversed {
+				sign = -1
+			}
+
+			const eps = 1e-6
+			for i, s := range states {
+				v, a := sign*s.Velocity, sign*s.Acceleration
+				if math.IsNaN(v) || math.IsNaN(a) || math.IsNaN(s.Curvature) {
+					t.Fatalf("state %v = %+v, want no NaNs", i, s)
+				}
+				if v < -eps || v > 300+eps {
+					t.Errorf("state %v velocity = %v, want from 0 to 300", i, s.Velocity)
+				}
+				if !test.unlimited && math.Abs(a) > 600+eps {
+					t.Errorf("state %v acceleration = %v, want at most 600", i, s.Acceleration)
+				}
+				if config.MaxCentripetalAcceleration > 0 && v*v*math.Abs(s.Curvature) > config.MaxCentripetalAcceleration+eps {
+					t.Errorf("state %v centripetal acceleration = %v, want at most %v", i, v*v*math.Abs(s.Curvature), config.MaxCentripetalAcceleration)
+				}
+				if i > 0 && s.Time <= states[i-1].Time {
+					t.Errorf("state %v time = %v, want after %v", i, s.Time, states[i-1].Time)
+				}
+			}
+
+			if v := sign * states[0].Velocity; math.Abs(v-config.StartVelocity) > eps {
+				t.Errorf("start velocity = %v, want %v", v, config.StartVelocity)
+			}
+			if v := sign * states[len(states)-1].Velocity; math.Abs(v-config.EndVelocity) > eps {
+				t.Errorf("end velocity = %v, want %v", v, config.EndVelocity)
+			}
+
+			if s := trajectory.Sample(-time.Second); s != states[0] {
+				t.Errorf("sample before start = %+v, want %+v", s, states[0])
+			}
+			if s := trajectory.Sample(trajectory.Duration() + time.Second); s != states[len(states)-1] {
+				t.Errorf("sample after end = %+v, want %+v", s, states[len(states)-1])
+			}
+		})
+	}
+}
+
+func TestTrajectoryExport(t *testing.T) {
+	trajectory := ev3lib.GenerateTrajectory([]ev3lib.Pose2d{{}, {X: 300, Y: 100, Heading: 30}}, ev3lib.CubicSpline, ev3lib.NewTrajectoryConfig(300, 600))
+	states := trajectory.States()
+
+	want := func(s ev3lib.TrajectoryState) []float64 {
+		return []float64{s.Time.Seconds(), s.Pose.X, s.Pose.Y, s.Pose.Heading, s.Velocity, s.Acceleration, s.Curvature}
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := trajectory.WriteCSV(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != len(states)+1 {
+			t.Fatalf("%v rows, want a header and %v states", len(rows), len(states))
+		}
+
+		header := []string{"time", "x", "y", "heading", "velocity", "acceleration", "curvature"}
+		for i, name := range header {
+			if rows[0][i] != name {
+				t.Errorf("header = %v, want %v", rows[0], header)
+				break
+			}
+		}
+
+		for i, s := range states {
+			for j, w := range want(s) {
+				got, err := strconv.ParseFloat(rows[i+1][j], 64)
+				if err != nil || got != w {
+					t.Fatalf("row %v column %v = %q, want %v", i+1, header[j], rows[i+1][j], w)
+				}
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := trajectory.WriteJSON(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded []map[string]float64
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if len(decoded) != len(states) {
+			t.Fatalf("%v states, want %v", len(decoded), len(states))
+		}
+
+		keys := []string{"time", "x", "y", "heading", "velocity", "acceleration", "curvature"}
+		for i, s := range states {
+			for j, w := range want(s) {
+				if got := decoded[i][keys[j]]; got != w {
+					t.Fatalf("state %v %v = %v, want %v", i, keys[j], got, w)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,192 @@
+package ev3lib
+
+import (
+	"slices"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Trigger                                                                    //
+////////////////////////////////////////////////////////////////////////////////
+
+// Trigger schedules and cancels commands on a Scheduler when a condition changes.
+// Bindings are checked every time the scheduler is run.
+type Trigger struct {
+	s    *Scheduler
+	cond func() bool
+}
+
+// NewTrigger creates a trigger from a condition, binding commands to the provided scheduler.
+func NewTrigger(s *Scheduler, cond func() bool) *Trigger {
+	return &Trigger{s: s, cond: cond}
+}
+
+// Get returns the current value of the trigger's condition.
+func (t *Trigger) Get() bool {
+	return t.cond()
+}
+
+// bind registers a function to be called on every scheduler run with the current and previous condition.
+func (t *Trigger) bind(f func(previous, current bool)) {
+	previous := false
+	first := true
+
+	t.s.bindings = append(t.s.bindings, func() {
+		current := t.cond()
+
+		if first {
+			previous = current
+			first = false
+		}
+
+		f(previous, current)
+		previous = current
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Trigger Bindings                                                           //
+////////////////////////////////////////////////////////////////////////////////
+
+// OnTrue schedules a command when the condition changes to true.
+func (t *Trigger) OnTrue(c *Command) *Trigger {
+	t.bind(func(previous, current bool) {
+		if !previous && current {
+			t.s.Schedule(c)
+		}
+	})
+	return t
+}
+
+// OnFalse schedules a command when the condition changes to false.
+func (t *Trigger) OnFalse(c *Command) *Trigger {
+	t.bind(func(previous, current bool) {
+		if previous && !current {
+			t.s.Schedule(c)
+		}
+	})
+	return t
+}
+
+// WhileTrue schedules a command when the condition changes to true and cancels it when the condition changes to false.
+func (t *Trigger) WhileTrue(c *Command) *Trigger {
+	t.bind(func(previous, current bool) {
+		if !previous && current {
+			t.s.Schedule(c)
+		} else if previous && !current {
+			t.s.Cancel(c)
+		}
+	})
+	return t
+}
+
+// ToggleOnTrue schedules a command when the condition changes to true, or cancels it if it is already running.
+func (t *Trigger) ToggleOnTrue(c *Command) *Trigger {
+	t.bind(func(previous, current bool) {
+		if !previous && current {
+			if t.s.IsScheduled(c) {
+				t.s.Cancel(c)
+			} else {
+				t.s.Schedule(c)
+			}
+		}
+	})
+	return t
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Trigger Combinators                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// And returns a trigger which is true when both triggers are true.
+func (t *Trigger) And(o *Trigger) *Trigger {
+	return NewTrigger(t.s, func() bool { return t.cond() && o.cond() })
+}
+
+// Or returns a trigger which is true when either trigger is true.
+func (t *Trigger) Or(o *Trigger) *Trigger {
+	return NewTrigger(t.s, func() bool { return t.cond() || o.cond() })
+}
+
+// Negate returns a trigger which is true when this trigger is false.
+func (t *Trigger) Negate() *Trigger {
+	return NewTrigger(t.s, func() bool { return !t.cond() })
+}
+
+// Debounce returns a trigger which only changes once the condition has held its new value for `dur`.
+func (t *Trigger) Debounce(dur time.Duration) *Trigger {
+	value := false
+	var changed time.Time
+	pending := false
+
+	return NewTrigger(t.s, func() bool {
+		current := t.cond()
+
+		if current == value {
+			pending = false
+			return value
+		}
+
+		if !pending {
+			pending = true
+			changed = Now()
+		}
+
+		if Since(changed) >= dur {
+			value = current
+			pending = false
+		}
+
+		return value
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Trigger Helpers                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// NewButtonTrigger creates a trigger which is true while a brick button is held.
+// Reading a brick button consumes its pressed and released edges, and the trigger reads it on every scheduler run,
+// so IsButtonPressed and IsButtonReleased elsewhere will miss edges of the same button. Bind commands to the trigger instead.
+func NewButtonTrigger(s *Scheduler, brick EV3BrickInterface, button EV3Button) *Trigger {
+	return NewTrigger(s, func() bool {
+		return brick.IsButtonPressed(button) || brick.IsButtonDown(button)
+	})
+}
+
+// NewTouchTrigger creates a trigger which is true while a touch sensor is pressed.
+func NewTouchTrigger(s *Scheduler, touch TouchSensorInterface) *Trigger {
+	return NewTrigger(s, touch.IsPressed)
+}
+
+// NewBeaconTrigger creates a trigger which is true while a beacon button is pressed on the provided channel.
+func NewBeaconTrigger(s *Scheduler, infrared InfraredSensorInterface, channel int, button BeaconButton) *Trigger {
+	return NewTrigger(s, func() bool {
+		return slices.Contains(infrared.Buttons(channel), button)
+	})
+}
+
+// NewAboveTrigger creates a trigger which is true while a value is above a threshold.
+func NewAboveTrigger(s *Scheduler, value func() float64, threshold float64) *Trigger {
+	return NewTrigger(s, func() bool { return value() > threshold })
+}
+
+// NewBelowTrigger creates a trigger which is true while a value is below a threshold.
+func NewBelowTrigger(s *Scheduler, value func() float64, threshold float64) *Trigger {
+	return NewTrigger(s, func() bool { return value() < threshold })
+}
+
+// NewReflectionAboveTrigger creates a trigger which is true while the reflected light intensity is above a threshold.
+func NewReflectionAboveTrigger(s *Scheduler, color ColorSensorInterface, threshold float64) *Trigger {
+	return NewAboveTrigger(s, color.Reflection, threshold)
+}
+
+// NewReflectionBelowTrigger creates a trigger which is true while the reflected light intensity is below a threshold.
+func NewReflectionBelowTrigger(s *Scheduler, color ColorSensorInterface, threshold float64) *Trigger {
+	return NewBelowTrigger(s, color.Reflection, threshold)
+}
+
+// NewDistanceBelowTrigger creates a trigger which is true while an ultrasonic sensor measures an object closer than a distance.
+func NewDistanceBelowTrigger(s *Scheduler, ultrasonic UltrasonicSensorInterface, distance float64) *Trigger {
+	return NewBelowTrigger(s, ultrasonic.Distance, distance)
+}
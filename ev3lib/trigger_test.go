@@ -0,0 +1,165 @@
+package ev3lib_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+func TestTriggerBindings(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		bind func(t *ev3lib.Trigger, c *ev3lib.Command) *ev3lib.Trigger
+
+		// values of the condition on each scheduler run, and whether the command should be scheduled after it
+		values    []bool
+		scheduled []bool
+	}{
+		{
+			"OnTrue",
+			(*ev3lib.Trigger).OnTrue,
+			[]bool{false, true, true, false, true},
+			[]bool{false, true, true, true, true},
+		},
+		{
+			"OnTrue starting true",
+			(*ev3lib.Trigger).OnTrue,
+			[]bool{true, true, false},
+			[]bool{false, false, false},
+		},
+		{
+			"OnFalse",
+			(*ev3lib.Trigger).OnFalse,
+			[]bool{true, true, false, false},
+			[]bool{false, false, true, true},
+		},
+		{
+			"WhileTrue",
+			(*ev3lib.Trigger).WhileTrue,
+			[]bool{false, true, true, false, true},
+			[]bool{false, true, true, false, true},
+		},
+		{
+			"ToggleOnTrue",
+			(*ev3lib.Trigger).ToggleOnTrue,
+			[]bool{false, true, false, true, false, true},
+			[]bool{false, true, true, false, false, true},
+		},
+	} {
+		s := ev3lib.NewScheduler()
+		c, _ := newRecordCommand(0)
+
+		value := false
+		test.bind(ev3lib.NewTrigger(s, func() bool { return value }), c)
+
+		for i, v := range test.values {
+			value = v
+			s.Run()
+
+			if got := s.IsScheduled(c); got != test.scheduled[i] {
+				t.Errorf("%v: scheduled = %v after run %d, want %v", test.name, got, i, test.scheduled[i])
+			}
+		}
+	}
+}
+
+func TestTriggerCombinators(t *testing.T) {
+	s := ev3lib.NewScheduler()
+
+	var a, b bool
+	ta := ev3lib.NewTrigger(s, func() bool { return a })
+	tb := ev3lib.NewTrigger(s, func() bool { return b })
+
+	and, or, not := ta.And(tb), ta.Or(tb), ta.Negate()
+
+	for _, test := range []struct {
+		a, b         bool
+		and, or, not bool
+	}{
+		{false, false, false, false, true},
+		{true, false, false, true, false},
+		{false, true, false, true, true},
+		{true, true, true, true, false},
+	} {
+		a, b = test.a, test.b
+		if and.Get() != test.and || or.Get() != test.or || not.Get() != test.not {
+			t.Errorf("a %v, b %v: and %v, or %v, not %v, want %v, %v, %v",
+				a, b, and.Get(), or.Get(), not.Get(), test.and, test.or, test.not)
+		}
+	}
+}
+
+func TestTriggerDebounce(t *testing.T) {
+	c := useManualClock(t)
+
+	value := false
+	d := ev3lib.NewTrigger(ev3lib.NewScheduler(), func() bool { return value }).Debounce(100 * time.Millisecond)
+
+	for _, step := range []struct {
+		value bool
+		after time.Duration
+		want  bool
+	}{
+		{true, 0, false},
+		{true, 50 * time.Millisecond, false},
+		{true, 50 * time.Millisecond, true},
+		// A short drop is ignored
+		{false, 0, true},
+		{false, 60 * time.Millisecond, true},
+		{true, 0, true},
+		{false, 0, true},
+		{false, 60 * time.Millisecond, true},
+		{false, 40 * time.Millisecond, false},
+	} {
+		value = step.value
+		c.Step(step.after)
+
+		if got := d.Get(); got != step.want {
+			t.Errorf("debounced value %v at %v, want %v", got, c.Elapsed(), step.want)
+		}
+	}
+}
+
+// buttonBrick reports a button press edge once, then the button as held.
+type buttonBrick struct {
+	ev3lib.EV3BrickInterface
+
+	edge, held bool
+}
+
+func (b *buttonBrick) IsButtonPressed(button ev3lib.EV3Button) bool {
+	edge := b.edge
+	b.edge = false
+	return button == ev3lib.Middle && edge
+}
+
+func (b *buttonBrick) IsButtonDown(button ev3lib.EV3Button) bool {
+	return button == ev3lib.Middle && b.held
+}
+
+func TestButtonTrigger(t *testing.T) {
+	s := ev3lib.NewScheduler()
+	brick := &buttonBrick{}
+	middle := ev3lib.NewButtonTrigger(s, brick, ev3lib.Middle)
+	left := ev3lib.NewButtonTrigger(s, brick, ev3lib.Left)
+
+	if middle.Get() {
+		t.Error("trigger true before the button is pressed")
+	}
+
+	brick.edge = true
+	if !middle.Get() {
+		t.Error("trigger false on the press edge")
+	}
+
+	brick.held = true
+	if !middle.Get() || left.Get() {
+		t.Error("trigger does not follow the held button")
+	}
+
+	brick.held = false
+	if middle.Get() {
+		t.Error("trigger true after the button is released")
+	}
+}
@@ -2,6 +2,7 @@ package ev3lib
 
 import (
 	"math"
+	"time"
 
 	"golang.org/x/exp/constraints"
 )
@@ -10,24 +11,185 @@ import (
 // PID Controller                                                             //
 ////////////////////////////////////////////////////////////////////////////////
 
+// PIDController drives a measurement towards a setpoint.
+// Optional settings such as output limits and continuous input are disabled until set.
 type PIDController struct {
 	kp, ki, kd float64
 
 	derivative, integral, lastError float64
+	lastSetPoint                    float64
+	setPointRate                    float64
+
+	integralMin, integralMax float64
+	integralZone             float64
+	derivativeFilter         float64
+
+	continuous         bool
+	inputMin, inputMax float64
+
+	outputMin, outputMax float64
+
+	positionTolerance, velocityTolerance float64
+
+	ff Feedforward
+
+	hasMeasurement bool
+	lastTime       time.Time
 }
 
 func NewPIDController(kp, ki, kd float64) *PIDController {
-	return &PIDController{kp, ki, kd, 0, 0, 0}
+	return &PIDController{
+		kp: kp, ki: ki, kd: kd,
+		integralMin: math.Inf(-1), integralMax: math.Inf(1),
+		outputMin: math.Inf(-1), outputMax: math.Inf(1),
+		positionTolerance: 0.05, velocityTolerance: math.Inf(1),
+	}
 }
 
+// Get returns the controller output, using the current clock to find the time since the last call.
+// The integral and derivative terms are zero on the first call.
 func (p *PIDController) Get(current, setPoint float64) float64 {
+	now := Now()
+
+	dt := 0.0
+	if !p.lastTime.IsZero() {
+		dt = now.Sub(p.lastTime).Seconds()
+	}
+	p.lastTime = now
+
+	return p.Calculate(current, setPoint, dt)
+}
+
+// Calculate returns the controller output for a time step in seconds since the last call.
+// The integral and derivative terms are not updated when dt is 0.
+func (p *PIDController) Calculate(current, setPoint, dt float64) float64 {
 	e := setPoint - current
+	setPointChange := setPoint - p.lastSetPoint
+	if p.continuous {
+		span := p.inputMax - p.inputMin
+		e = math.Remainder(e, span)
+		setPointChange = math.Remainder(setPointChange, span)
+	}
+
+	// The integral before this step, restored if the output saturates
+	prevIntegral, integrated := p.integral, false
+
+	if p.hasMeasurement && dt > 0 {
+		// Low pass filter the derivative, a time constant of 0 disables the filter
+		raw := (e - p.lastError) / dt
+		alpha := dt / (p.derivativeFilter + dt)
+		p.derivative += alpha * (raw - p.derivative)
 
-	p.derivative = p.lastError - e
-	p.integral = p.integral/2 + e
+		p.setPointRate = setPointChange / dt
+
+		if p.integralZone > 0 && math.Abs(e) > p.integralZone {
+			p.integral = 0
+		} else {
+			p.integral += e * dt
+			integrated = true
+		}
+	}
+	p.hasMeasurement = true
 	p.lastError = e
+	p.lastSetPoint = setPoint
+
+	// Clamp the integral term to its range, keeping the stored integral from winding up
+	if p.ki != 0 {
+		i := Clamp(p.integral*p.ki, p.integralMin, p.integralMax)
+		p.integral = i / p.ki
+	}
+
+	out := e*p.kp + p.integral*p.ki + p.derivative*p.kd + p.feedforward(e)
+
+	// Stop integrating while the output is saturated in the direction of the error
+	if out > p.outputMax || out < p.outputMin {
+		if integrated && math.Signbit(e) == math.Signbit(out) {
+			p.integral = prevIntegral
+		}
+		out = Clamp(out, p.outputMin, p.outputMax)
+	}
+
+	return out
+}
+
+// feedforward returns the static and velocity feedforward for the setpoint.
+// The static term acts in the direction the setpoint is moving, or towards the setpoint while outside tolerance.
+func (p *PIDController) feedforward(e float64) float64 {
+	velocity := p.setPointRate
+	out := p.ff.Calculate(velocity, 0)
+	if velocity == 0 && math.Abs(e) > p.positionTolerance {
+		out += math.Copysign(p.ff.Ks, e)
+	}
+	return out
+}
+
+// Reset clears the integral and derivative state, use when starting to control something new.
+func (p *PIDController) Reset() {
+	p.derivative = 0
+	p.integral = 0
+	p.lastError = 0
+	p.lastSetPoint = 0
+	p.setPointRate = 0
+	p.hasMeasurement = false
+	p.lastTime = time.Time{}
+}
+
+// AtSetpoint returns whether the last error and its rate of change are within tolerance.
+func (p *PIDController) AtSetpoint() bool {
+	return p.hasMeasurement &&
+		math.Abs(p.lastError) <= p.positionTolerance &&
+		math.Abs(p.derivative) <= p.velocityTolerance
+}
+
+// SetTolerance sets the error and error rate within which AtSetpoint returns true.
+// Defaults to an error of 0.05 and any rate.
+func (p *PIDController) SetTolerance(position, velocity float64) {
+	p.positionTolerance = position
+	p.velocityTolerance = velocity
+}
+
+// LastError returns the error from the last call.
+func (p *PIDController) LastError() float64 {
+	return p.lastError
+}
+
+// SetIntegratorRange limits the contribution of the integral term to the output.
+func (p *PIDController) SetIntegratorRange(min, max float64) {
+	p.integralMin = min
+	p.integralMax = max
+}
+
+// SetIntegralZone only integrates while the error is within zone of the setpoint, clearing it outside. 0 disables the zone.
+func (p *PIDController) SetIntegralZone(zone float64) {
+	p.integralZone = zone
+}
+
+// SetDerivativeFilter low pass filters the derivative term with a time constant, reducing noise from the sensor. 0 disables the filter.
+func (p *PIDController) SetDerivativeFilter(timeConstant time.Duration) {
+	p.derivativeFilter = timeConstant.Seconds()
+}
+
+// EnableContinuousInput treats min and max as the same point, e.g. -180 and 180 degrees, so errors take the shortest way around.
+func (p *PIDController) EnableContinuousInput(min, max float64) {
+	p.continuous = true
+	p.inputMin = min
+	p.inputMax = max
+}
+
+func (p *PIDController) DisableContinuousInput() {
+	p.continuous = false
+}
+
+// SetOutputLimits clamps the output, and stops the integral winding up while it is clamped.
+func (p *PIDController) SetOutputLimits(min, max float64) {
+	p.outputMin = min
+	p.outputMax = max
+}
 
-	return (e * p.kp) + (p.integral * p.ki) + (p.derivative * p.kd)
+// SetFeedforward adds static and velocity feedforward, using the rate the setpoint is changing as the velocity.
+// The acceleration term is not used.
+func (p *PIDController) SetFeedforward(ff Feedforward) {
+	p.ff = ff
 }
 
 func (p *PIDController) Kp() float64 {
@@ -0,0 +1,65 @@
+package ev3lib_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Alanlu217/ev3lib/ev3lib"
+)
+
+func TestPIDAntiWindup(t *testing.T) {
+	type step struct{ current, setPoint, dt float64 }
+
+	for _, test := range []struct {
+		name  string
+		setup func(p *ev3lib.PIDController)
+		steps []step
+		// Output for an error of 0 afterwards, which is only the integral term
+		want float64
+	}{
+		{
+			name:  "integrates",
+			steps: []step{{0, 0.2, 0}, {0, 0.2, 1}, {0, 0.2, 1}},
+			want:  0.4,
+		},
+		{
+			name:  "saturated",
+			setup: func(p *ev3lib.PIDController) { p.SetOutputLimits(-1, 1) },
+			steps: []step{{0, 0.5, 0}, {0, 0.5, 1}, {0, 2, 1}},
+			want:  0.5,
+		},
+		{
+			name: "integral zone",
+			setup: func(p *ev3lib.PIDController) {
+				p.SetOutputLimits(-1, 1)
+				p.SetIntegralZone(5)
+			},
+			steps: []step{{0, 1, 0}, {0, 10, 0.1}},
+			want:  0,
+		},
+		{
+			name: "integrator range",
+			setup: func(p *ev3lib.PIDController) {
+				p.SetOutputLimits(-1, 1)
+				p.SetIntegratorRange(-0.5, 0.5)
+			},
+			steps: []step{{0, 0.1, 0}, {0, 0.9, 1}},
+			want:  0,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			p := ev3lib.NewPIDController(1, 1, 0)
+			if test.setup != nil {
+				test.setup(p)
+			}
+
+			for _, s := range test.steps {
+				p.Calculate(s.current, s.setPoint, s.dt)
+			}
+
+			if got := p.Calculate(0, 0, 1); math.Abs(got-test.want) > 1e-9 {
+				t.Errorf("integral output = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
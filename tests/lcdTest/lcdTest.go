@@ -12,6 +12,9 @@ func main() {
 	hub := ev3.NewEV3()
 	t := time.NewTicker(time.Second)
 
+	buffer := ev3.NewBackBuffer()
+	g := buffer.Graphics()
+
 	for {
 		start := time.Now()
 		hub.ClearScreen()
@@ -27,5 +30,15 @@ func main() {
 		fmt.Println(time.Since(start))
 
 		<-t.C
+
+		start = time.Now()
+		g.Clear()
+
+		g.DrawText(0, 19*0, "abcdefghijklmnop")
+		g.DrawText(0, 19*1, time.Now().Format("15:04:05"))
+
+		fmt.Println(time.Since(start), buffer.Flush())
+
+		<-t.C
 	}
 }
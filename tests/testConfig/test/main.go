@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/Alanlu217/ev3lib/ev3lib"
@@ -11,16 +12,28 @@ import (
 func main() {
 	config := &testConfig.Config{}
 
+	world := testUtils.NewSimWorld(testUtils.SimRobotConfig{
+		WheelDiameter: 56,
+		WheelBase:     120,
+		LeftModel:     testUtils.LargeMotorModel,
+		RightModel:    testUtils.LargeMotorModel,
+	})
+	world.AddBorder(2362, 1143)
+	world.SetPose(300, 300, 0)
+
 	config.Ev3 = testUtils.NewTestEV3Brick()
 
-	config.Gyro = testUtils.NewTestGyroSensor()
+	config.Gyro = world.NewGyroSensor()
 
-	config.LeftColor = testUtils.NewTestColorSensor()
-	config.CentreColor = testUtils.NewTestColorSensor()
-	config.RightColor = testUtils.NewTestColorSensor()
+	config.LeftColor = world.NewColorSensor(60, 30)
+	config.CentreColor = world.NewColorSensor(60, 0)
+	config.RightColor = world.NewColorSensor(60, -30)
 
-	config.LeftDrive = testUtils.NewTestMotor("Left Drive")
-	config.RightDrive = testUtils.NewTestMotor("Right Drive Motor")
+	config.LeftDrive = world.LeftMotor()
+	config.RightDrive = world.RightMotor()
 
 	ev3lib.RunTimedCommand(config.GetCommandPages().Pages[0].Commands[8], 20*time.Millisecond)
+
+	x, y, heading := world.Pose()
+	fmt.Printf("Robot ended at (%.0f, %.0f) facing %.0f\n", x, y, heading)
 }